@@ -0,0 +1,227 @@
+package miniconda
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+// CondaBindingType is the type of the service bindings that provide the
+// credentials of private conda channels.
+const CondaBindingType = "conda"
+
+// ChannelAuth holds the credentials of a private conda channel provided by a
+// conda service binding. A binding has a channel entry with the URL of the
+// channel and either a token entry or username and password entries. When a
+// username is given together with a token, the token is used as the password.
+type ChannelAuth struct {
+	Binding  string
+	Channel  string
+	Token    string
+	Username string
+	Password string
+}
+
+// resolveChannelAuth returns the credentials of every conda binding.
+func resolveChannelAuth(platformPath string, bindingResolver BindingResolver) ([]ChannelAuth, error) {
+	bindings, err := bindingResolver.Resolve(CondaBindingType, "", platformPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var auths []ChannelAuth
+	for _, binding := range bindings {
+		auth := ChannelAuth{Binding: binding.Name}
+		for name, value := range map[string]*string{
+			"channel":  &auth.Channel,
+			"token":    &auth.Token,
+			"username": &auth.Username,
+			"password": &auth.Password,
+		} {
+			entry, ok := binding.Entries[name]
+			if !ok {
+				continue
+			}
+
+			content, err := entry.ReadString()
+			if err != nil {
+				return nil, err
+			}
+			*value = strings.TrimSpace(content)
+		}
+
+		if auth.Channel == "" {
+			return nil, fmt.Errorf("binding '%s' of type '%s' is missing required entry 'channel'", binding.Name, CondaBindingType)
+		}
+
+		if auth.Username != "" && auth.Password == "" {
+			auth.Password = auth.Token
+			auth.Token = ""
+		}
+
+		if auth.Token == "" && (auth.Username == "" || auth.Password == "") {
+			return nil, fmt.Errorf("binding '%s' of type '%s' must have either a 'token' entry or 'username' and 'password' entries", binding.Name, CondaBindingType)
+		}
+
+		_, err := auth.URL()
+		if err != nil {
+			return nil, err
+		}
+
+		auths = append(auths, auth)
+	}
+
+	return auths, nil
+}
+
+// URL returns the channel URL including the credentials, in the form
+// understood by conda: tokens are inserted as a /t/<token> path prefix, while
+// usernames and passwords are added as user information.
+func (a ChannelAuth) URL() (string, error) {
+	uri, err := url.Parse(a.Channel)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse channel of binding '%s': %w", a.Binding, err)
+	}
+
+	if (uri.Scheme != "http" && uri.Scheme != "https") || uri.Host == "" {
+		return "", fmt.Errorf("failed to parse channel of binding '%s': %q is not an http(s) URL", a.Binding, a.Channel)
+	}
+
+	if a.Token != "" {
+		uri.Path = "/t/" + a.Token + "/" + strings.TrimPrefix(uri.Path, "/")
+		uri.RawPath = ""
+	} else {
+		uri.User = url.UserPassword(a.Username, a.Password)
+	}
+
+	return uri.String(), nil
+}
+
+// Secrets returns the values that must never end up in a layer, in both their
+// plain and URL encoded forms.
+func (a ChannelAuth) Secrets() []string {
+	var secrets []string
+	for _, secret := range []string{a.Token, a.Password} {
+		if secret == "" {
+			continue
+		}
+
+		secrets = append(secrets, secret)
+		for _, escaped := range []string{url.PathEscape(secret), url.QueryEscape(secret)} {
+			if escaped != secret {
+				secrets = append(secrets, escaped)
+			}
+		}
+	}
+
+	return secrets
+}
+
+// authenticatedChannels returns the channel URLs including the credentials
+// of the given channels.
+func authenticatedChannels(auths []ChannelAuth) ([]string, error) {
+	var channels []string
+	for _, auth := range auths {
+		channel, err := auth.URL()
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, channel)
+	}
+
+	return channels, nil
+}
+
+// redactSecrets replaces the given secrets in the package records and
+// package cache indexes that conda writes into the prefix at layerPath, as
+// those include the URLs that the packages were downloaded from.
+func redactSecrets(layerPath string, secrets []string) error {
+	for _, dir := range []string{"conda-meta", "pkgs"} {
+		err := redactDir(filepath.Join(layerPath, dir), secrets)
+		if err != nil {
+			return fmt.Errorf("failed to redact credentials from %s: %w", layerPath, err)
+		}
+	}
+
+	return nil
+}
+
+// redactPackageCache replaces the given secrets in the package cache at
+// cachePath, which records the download URLs of the packages in urls.txt,
+// the repodata_record.json of every package and the index cache.
+func redactPackageCache(cachePath string, secrets []string) error {
+	err := redactDir(cachePath, secrets)
+	if err != nil {
+		return fmt.Errorf("failed to redact credentials from %s: %w", cachePath, err)
+	}
+
+	return nil
+}
+
+func redactDir(dir string, secrets []string) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if !entry.Type().IsRegular() || !redactable(entry.Name()) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		redacted := string(content)
+		for _, secret := range secrets {
+			redacted = strings.ReplaceAll(redacted, secret, "<redacted>")
+		}
+
+		if redacted == string(content) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(path, []byte(redacted), info.Mode().Perm())
+	})
+}
+
+func redactable(name string) bool {
+	switch filepath.Ext(name) {
+	case ".json", ".txt":
+		return true
+	}
+
+	return name == "history" || name == "urls"
+}
+
+// condaEnvRunner adds build-only environment variables to every execution of
+// conda.
+type condaEnvRunner struct {
+	runner CondaRunner
+	env    []string
+}
+
+func (r condaEnvRunner) Execute(condaLayerPath string, execution pexec.Execution) error {
+	execution.Env = append(execution.Env, r.env...)
+	return r.runner.Execute(condaLayerPath, execution)
+}
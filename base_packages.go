@@ -0,0 +1,154 @@
+package miniconda
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// SolverPackage is the package that provides the libmamba solver.
+const SolverPackage = "conda-libmamba-solver"
+
+var packageNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-]*$`)
+
+// ParseBasePackages converts a whitespace separated list of conda package
+// specs, such as "conda-build pip>=23,<24", into a list of specs. Specs are
+// separated by whitespace rather than commas because version constraints may
+// contain commas.
+func ParseBasePackages(value string) ([]string, error) {
+	var specs []string
+	for _, spec := range strings.Fields(value) {
+		if !packageNamePattern.MatchString(specName(spec)) {
+			return nil, fmt.Errorf("invalid package spec %q", spec)
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+// lookupBasePackages reads the package specs to install into the base
+// environment from $BP_CONDA_BASE_PACKAGES.
+func lookupBasePackages(config Config) ([]string, error) {
+	specs, err := ParseBasePackages(config.Lookup("BP_CONDA_BASE_PACKAGES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", config.Name("BP_CONDA_BASE_PACKAGES"), err)
+	}
+
+	return specs, nil
+}
+
+// installBasePackages installs the given package specs and, when
+// installSolver is true, the conda-libmamba-solver package into the base
+// environment of the conda layer in a single solve. When solverChannel is the
+// solver channel dependency, the solver is installed from that channel,
+// offline unless other packages are requested. Otherwise, solverChannel is
+// empty and all packages are installed from the configured channels. The
+// classic solver is used for the installation because the generated .condarc
+// may already select the libmamba solver.
+func installBasePackages(
+	context packit.BuildContext,
+	dependencyManager DependencyManager,
+	condaRunner CondaRunner,
+	condaLayer packit.Layer,
+	installSolver bool,
+	solverChannel postal.Dependency,
+	specs []string,
+	logger scribe.Emitter,
+) error {
+	args := []string{"install", "-n", "base", "--solver", "classic"}
+
+	if installSolver {
+		if solverChannel.ID == "" {
+			logger.Action("No %s dependency available, installing from the configured channels", SolverChannelID)
+		} else {
+			channel, err := deliverSolverChannel(context, dependencyManager, solverChannel, logger)
+			if err != nil {
+				return err
+			}
+
+			if len(specs) == 0 {
+				args = append(args, "--offline", "--override-channels")
+			}
+
+			args = append(args, "--channel", "file://"+channel)
+		}
+
+		specs = append([]string{SolverPackage}, specs...)
+	}
+
+	args = append(args, specs...)
+	args = append(args, "-y")
+
+	return condaRunner.Execute(condaLayer.Path, pexec.Execution{
+		Args: args,
+	})
+}
+
+// hasCondaPackage returns whether the package with the given name is installed
+// into the base environment of the conda prefix at dir. Recent Miniconda and
+// Miniforge installers already include the libmamba solver, which then does
+// not need to be installed.
+func hasCondaPackage(dir, name string) (bool, error) {
+	packages, err := CondaPackages(dir)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range packages {
+		if p.Name == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// resolveSolverChannel returns the solver channel dependency, or an empty
+// dependency when buildpack.toml does not contain it.
+func resolveSolverChannel(context packit.BuildContext, dependencyManager DependencyManager) (postal.Dependency, error) {
+	dependency, err := dependencyManager.Resolve(filepath.Join(context.CNBPath, "buildpack.toml"), SolverChannelID, "*", context.Stack)
+	if err != nil {
+		var noDeps *postal.ErrNoDeps
+		if errors.As(err, &noDeps) {
+			return postal.Dependency{}, nil
+		}
+
+		return postal.Dependency{}, err
+	}
+
+	return dependency, nil
+}
+
+// deliverSolverChannel delivers the solver channel dependency into a temporary
+// layer and returns its path.
+func deliverSolverChannel(context packit.BuildContext, dependencyManager DependencyManager, dependency postal.Dependency, logger scribe.Emitter) (string, error) {
+	// Like the installer script layer, this layer has no type set to true, so
+	// the lifecycle will ensure that it is removed.
+	channelLayer, err := context.Layers.Get("libmamba-solver-channel-temp-layer")
+	if err != nil {
+		return "", err
+	}
+
+	channelLayer, err = channelLayer.Reset()
+	if err != nil {
+		return "", err
+	}
+
+	err = dependencyManager.Deliver(dependency, context.CNBPath, channelLayer.Path, context.Platform.Path)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Action("Using local channel from %s %s", SolverChannelID, dependency.Version)
+
+	return channelLayer.Path, nil
+}
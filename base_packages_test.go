@@ -0,0 +1,44 @@
+package miniconda_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testBasePackages(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseBasePackages", func() {
+		it("parses a whitespace separated list of package specs", func() {
+			specs, err := miniconda.ParseBasePackages(" conda-build\tconda-forge::conda-pack  pip>=23,<24\nruamel.yaml=0.17 ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(specs).To(Equal([]string{"conda-build", "conda-forge::conda-pack", "pip>=23,<24", "ruamel.yaml=0.17"}))
+		})
+
+		it("returns nothing for an empty list", func() {
+			specs, err := miniconda.ParseBasePackages("  ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(specs).To(BeEmpty())
+		})
+
+		context("failure cases", func() {
+			context("when a spec has no package name", func() {
+				it("returns an error", func() {
+					_, err := miniconda.ParseBasePackages("pip >=23")
+					Expect(err).To(MatchError(`invalid package spec ">=23"`))
+				})
+			})
+
+			context("when a spec has an invalid package name", func() {
+				it("returns an error", func() {
+					_, err := miniconda.ParseBasePackages("conda-build,pip")
+					Expect(err).To(MatchError(`invalid package spec "conda-build,pip"`))
+				})
+			})
+		})
+	})
+}
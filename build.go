@@ -1,22 +1,28 @@
 package miniconda
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/paketo-buildpacks/packit/v2"
-	"github.com/paketo-buildpacks/packit/v2/cargo"
 	"github.com/paketo-buildpacks/packit/v2/chronos"
 	"github.com/paketo-buildpacks/packit/v2/draft"
 	"github.com/paketo-buildpacks/packit/v2/pexec"
 	"github.com/paketo-buildpacks/packit/v2/postal"
 	"github.com/paketo-buildpacks/packit/v2/sbom"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
 )
 
 //go:generate faux --interface DependencyManager --output fakes/dependency_manager.go
-//go:generate faux --interface Runner --output fakes/runner.go
+//go:generate faux --interface Installer --output fakes/installer.go
+//go:generate faux --interface CondaRunner --output fakes/conda_runner.go
+//go:generate faux --interface BindingResolver --output fakes/binding_resolver.go
 //go:generate faux --interface SBOMGenerator --output fakes/sbom_generator.go
 
 // DependencyManager defines the interface for picking the best matching
@@ -27,9 +33,21 @@ type DependencyManager interface {
 	GenerateBillOfMaterials(dependencies ...postal.Dependency) []packit.BOMEntry
 }
 
-// Runner defines the interface for invoking the miniconda script downloaded as a dependency.
-type Runner interface {
-	Run(runPath, layerPath string) error
+// Installer defines the interface for installing a dependency that has been
+// delivered to artifactPath, such as the miniconda script, into a layer.
+type Installer interface {
+	Install(dependency postal.Dependency, artifactPath, layerPath string, options InstallOptions) error
+}
+
+// CondaRunner defines the interface for invoking the conda executable that has
+// been installed into a layer.
+type CondaRunner interface {
+	Execute(condaLayerPath string, execution pexec.Execution) error
+}
+
+// BindingResolver defines the interface for looking up service bindings.
+type BindingResolver interface {
+	Resolve(typ, provider, platformDir string) ([]servicebindings.Binding, error)
 }
 
 type SBOMGenerator interface {
@@ -47,13 +65,36 @@ func GetEnvOrDefault(key, defaultValue string) string {
 // Build will return a packit.BuildFunc that will be invoked during the build
 // phase of the buildpack lifecycle.
 //
-// Build will find the right miniconda dependency to download, download it
-// into a layer, run the miniconda script to install conda into a separate
-// layer and generate Bill-of-Materials. It also makes use of the checksum of
-// the dependency to reuse the layer when possible.
+// Build will find the right dependency of the distribution selected by
+// $BP_CONDA_DISTRIBUTION to download, download it into a layer, run the
+// installer that the dependency requires to install conda into a separate
+// layer, verify that the installed conda matches the dependency, write the
+// conda configuration into that layer and generate Bill-of-Materials. It
+// records every input of the layer, such as the checksum of the dependency,
+// the distribution, the configured solver and the checksum of the conda
+// configuration, in the layer metadata to reuse the layer when none of them
+// changed, and logs the inputs that changed otherwise.
+//
+// Credentials of private channels provided through conda service bindings and
+// additional CA certificates are only available to conda during the build.
+// Credentials are redacted from the package records that conda writes into
+// the layers.
+//
+// The package specs of $BP_CONDA_BASE_PACKAGES are installed into the base
+// environment of the conda layer, together with the mamba solver when it is
+// configured, in a single solve right after the installer.
+//
+// When $BP_CONDA_ENV_INSTALL is true, Build will also install the
+// application's lock file or environment.yml into a separate launch layer.
+// Whenever conda installs packages, it uses a package cache in a cache-only
+// layer that is kept across builds. When the conda layer is only required at
+// launch, Build slims it down according to $BP_CONDA_CLEAN and
+// $BP_CONDA_CLEAN_PATTERNS.
 func Build(
 	dependencyManager DependencyManager,
-	runner Runner,
+	installer Installer,
+	condaRunner CondaRunner,
+	bindingResolver BindingResolver,
 	sbomGenerator SBOMGenerator,
 	logger scribe.Emitter,
 	clock chronos.Clock,
@@ -61,13 +102,77 @@ func Build(
 	return func(context packit.BuildContext) (packit.BuildResult, error) {
 		logger.Title("%s %s", context.BuildpackInfo.Name, context.BuildpackInfo.Version)
 
+		config, err := LoadConfig(context.WorkingDir)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		config.summary(logger)
+
 		planner := draft.NewPlanner()
 
-		dependency, err := dependencyManager.Resolve(filepath.Join(context.CNBPath, "buildpack.toml"), "miniconda3", "*", context.Stack)
+		entries := context.Plan.Entries
+		if version := config.Lookup("BP_MINICONDA_VERSION", ""); version != "" {
+			entries = append(entries, packit.BuildpackPlanEntry{
+				Name: "conda",
+				Metadata: map[string]interface{}{
+					"version":        version,
+					"version-source": config.Source("BP_MINICONDA_VERSION"),
+				},
+			})
+		}
+
+		distribution, err := lookupDistribution(config)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		solver, err := lookupSolver(config, logger)
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
+		basePackages, err := lookupBasePackages(config)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		envInstall, err := strconv.ParseBool(GetEnvOrDefault("BP_CONDA_ENV_INSTALL", "false"))
+		if err != nil {
+			return packit.BuildResult{}, fmt.Errorf("failed to parse BP_CONDA_ENV_INSTALL: %w", err)
+		}
+
+		cleanup, err := lookupCleanup(config)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		logger.Process("Resolving conda version")
+		entry, sortedEntries := planner.Resolve("conda", entries, Priorities)
+		logger.Candidates(sortedEntries)
+
+		// Entries that do not declare a version constraint never outrank
+		// entries that do, regardless of their version-source.
+		version := "*"
+		for _, e := range sortedEntries {
+			if v, ok := e.Metadata["version"].(string); ok && v != "" {
+				entry, version = e, v
+				break
+			}
+		}
+
+		dependency, err := dependencyManager.Resolve(filepath.Join(context.CNBPath, "buildpack.toml"), distribution.DependencyID(), version, context.Stack)
+		if err != nil {
+			source, ok := entry.Metadata["version-source"].(string)
+			if !ok {
+				source = "<unknown>"
+			}
+
+			return packit.BuildResult{}, fmt.Errorf("failed to resolve conda version %q requested by %s: %w", version, source, err)
+		}
+
+		logger.SelectedDependency(entry, dependency, clock.Now())
+
 		legacySBOM := dependencyManager.GenerateBillOfMaterials(dependency)
 
 		condaLayer, err := context.Layers.Get("conda")
@@ -75,7 +180,7 @@ func Build(
 			return packit.BuildResult{}, err
 		}
 
-		launch, build := planner.MergeLayerTypes("conda", context.Plan.Entries)
+		launch, build := planner.MergeLayerTypes("conda", entries)
 
 		var buildMetadata = packit.BuildMetadata{}
 		var launchMetadata = packit.LaunchMetadata{}
@@ -87,124 +192,275 @@ func Build(
 			launchMetadata = packit.LaunchMetadata{BOM: legacySBOM}
 		}
 
-		cachedChecksum, ok := condaLayer.Metadata[DepKey].(string)
-		dependencyChecksum := dependency.Checksum
-		if dependencyChecksum == "" {
-			//nolint:staticcheck // SHA256 is only a fallback in case Checksum is not present
-			dependencyChecksum = dependency.SHA256
+		// Cleaning only applies to a layer that is exclusively used at launch,
+		// so that the tooling used by subsequent buildpacks stays intact.
+		effectiveCleanup := Cleanup{Mode: CleanNone}
+		if launch && !build {
+			effectiveCleanup = cleanup
 		}
 
-		if ok && cachedChecksum != "" && cargo.Checksum(cachedChecksum).MatchString(dependencyChecksum) {
-			logger.Process("Reusing cached layer %s", condaLayer.Path)
-			logger.Break()
-
-			condaLayer.Launch, condaLayer.Build, condaLayer.Cache = launch, build, build
-
-			return packit.BuildResult{
-				Layers: []packit.Layer{condaLayer},
-				Build:  buildMetadata,
-				Launch: launchMetadata,
-			}, nil
-		}
-
-		condaLayer, err = condaLayer.Reset()
+		condarc, err := generateCondarc(context.WorkingDir, context.Platform.Path, bindingResolver, solver, config)
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
-		condaLayer.Launch, condaLayer.Build, condaLayer.Cache = launch, build, build
-
-		// This temporary layer is created because the path to a deterministic and
-		// easier to make assertions about during testing. Because this layer has
-		// no type set to true the lifecycle will ensure that this layer is
-		// removed.
-		minicondaScriptTempLayer, err := context.Layers.Get("miniconda-script-temp-layer")
+		condarcChecksum, err := condarc.Checksum()
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
-		minicondaScriptTempLayer, err = minicondaScriptTempLayer.Reset()
+		executor, secrets, err := configureBuildConda(context, condaRunner, bindingResolver, logger)
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
-		logger.Process("Executing build process")
-		logger.Subprocess("Installing Miniconda %s", dependency.Version)
+		// The package cache is only kept when conda installs packages as part of
+		// the build.
+		var packageCache PackageCache
+		var cachePackages bool
+		if solver == LibmambaSolver || len(basePackages) > 0 || envInstall {
+			packageCache, cachePackages, err = preparePackageCache(context, logger)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if cachePackages {
+				executor = condaEnvRunner{runner: executor, env: []string{fmt.Sprintf("CONDA_PKGS_DIRS=%s", packageCache.Layer.Path)}}
+			}
+		}
 
-		duration, err := clock.Measure(func() error {
-			err := dependencyManager.Deliver(dependency, context.CNBPath, minicondaScriptTempLayer.Path, context.Platform.Path)
+		// The solver channel is an input of the conda layer whenever the solver
+		// may have to be installed from it.
+		var solverChannel postal.Dependency
+		if solver == LibmambaSolver {
+			solverChannel, err = resolveSolverChannel(context, dependencyManager)
 			if err != nil {
-				return err
+				return packit.BuildResult{}, err
 			}
+		}
 
-			scriptPath := filepath.Join(minicondaScriptTempLayer.Path, dependency.Name)
-			return runner.Run(scriptPath, condaLayer.Path)
-		})
-		if err != nil {
-			return packit.BuildResult{}, err
+		record := CondaLayerMetadata{
+			DependencyChecksum:    dependencyChecksum(dependency),
+			Distribution:          string(distribution),
+			CondarcChecksum:       condarcChecksum,
+			Solver:                string(solver),
+			SolverChannelChecksum: dependencyChecksum(solverChannel),
+			BasePackages:          basePackages,
+			Cleanup:               effectiveCleanup.String(),
+			BuildpackVersion:      context.BuildpackInfo.Version,
 		}
 
-		logger.Action("Completed in %s", duration.Round(time.Millisecond))
-		logger.Break()
+		cachedRecord := ParseCondaLayerMetadata(condaLayer.Metadata)
+		changes := record.Changes(cachedRecord)
+		if len(changes) == 0 {
+			logger.Process("Reusing cached layer %s", condaLayer.Path)
+			if condaVersion, ok := condaLayer.Metadata[CondaVersionKey].(string); ok {
+				logger.Subprocess("conda %s, Python %s (%s)", condaVersion, condaLayer.Metadata[PythonVersionKey], condaLayer.Metadata[PlatformKey])
+			}
+			logger.Break()
 
-		solver := GetEnvOrDefault("BP_CONDA_SOLVER", "conda")
+			// Layers built before the fingerprint was recorded are brought up to
+			// date.
+			condaLayer.Metadata[FingerprintKey] = record.Fingerprint()
+			condaLayer.Launch, condaLayer.Build, condaLayer.Cache = launch, build, build
+		} else {
+			if cachedRecord.DependencyChecksum != "" {
+				logger.Process("Rebuilding layer %s: %s", condaLayer.Path, strings.Join(changes, ", "))
+				logger.Break()
+			}
 
-		if solver == "mamba" {
-			logger.Subprocess("Installing mamba solver")
+			condaLayer, err = condaLayer.Reset()
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
 
-			conda := pexec.NewExecutable(condaLayer.Path + "/bin/conda")
-			duration, err = clock.Measure(func() error {
-				return conda.Execute(pexec.Execution{
-					Args: []string{"install", "-n", "base", "conda-libmamba-solver", "-y"},
-				})
+			condaLayer.Launch, condaLayer.Build, condaLayer.Cache = launch, build, build
+
+			// This temporary layer is created because the path to a deterministic and
+			// easier to make assertions about during testing. Because this layer has
+			// no type set to true the lifecycle will ensure that this layer is
+			// removed.
+			minicondaScriptTempLayer, err := context.Layers.Get("miniconda-script-temp-layer")
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			minicondaScriptTempLayer, err = minicondaScriptTempLayer.Reset()
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			logger.Process("Executing build process")
+			logger.Subprocess("Installing %s %s", distribution.Title(), dependency.Version)
+
+			duration, err := clock.Measure(func() error {
+				err := dependencyManager.Deliver(dependency, context.CNBPath, minicondaScriptTempLayer.Path, context.Platform.Path)
+				if err != nil {
+					return err
+				}
+
+				return installer.Install(dependency, minicondaScriptTempLayer.Path, condaLayer.Path, InstallOptions{})
 			})
 			if err != nil {
 				return packit.BuildResult{}, err
 			}
 
-			logger.Action("Solver completed in %s", duration.Round(time.Millisecond))
+			logger.Action("Completed in %s", duration.Round(time.Millisecond))
 			logger.Break()
 
-			logger.Subprocess("Configuring mamba solver")
-			duration, err = clock.Measure(func() error {
-				return conda.Execute(pexec.Execution{
-					Args: []string{"config", "--set", "solver", "libmamba"},
+			logger.Subprocess("Verifying installation")
+			info, err := verifyInstallation(condaRunner, condaLayer, dependency)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			logger.Action("conda %s, Python %s (%s)", info.CondaVersion, info.PythonVersion, info.Platform)
+			logger.Break()
+
+			if !condarc.Empty() {
+				logger.Subprocess("Writing conda configuration to %s", filepath.Join(condaLayer.Path, ".condarc"))
+				logger.Action("Using configuration from: %s", strings.Join(condarc.Sources, ", "))
+				logger.Break()
+
+				content, err := condarc.Marshal()
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				err = os.WriteFile(filepath.Join(condaLayer.Path, ".condarc"), content, 0644)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+			}
+
+			installSolver := solver == LibmambaSolver
+			if installSolver {
+				bundled, err := hasCondaPackage(condaLayer.Path, SolverPackage)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				if bundled {
+					logger.Subprocess("Using the mamba solver bundled with %s %s", distribution.Title(), dependency.Version)
+					logger.Break()
+
+					installSolver = false
+				}
+			}
+
+			if installSolver || len(basePackages) > 0 {
+				if len(basePackages) == 0 {
+					logger.Subprocess("Installing mamba solver")
+				} else {
+					logger.Subprocess("Installing base packages: %s", strings.Join(basePackages, " "))
+				}
+
+				duration, err = clock.Measure(func() error {
+					return installBasePackages(context, dependencyManager, executor, condaLayer, installSolver, solverChannel, basePackages, logger)
 				})
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				err = redactSecrets(condaLayer.Path, secrets)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				logger.Action("Completed in %s", duration.Round(time.Millisecond))
+				logger.Break()
+			}
+
+			if !effectiveCleanup.Empty() {
+				logger.Process("Cleaning conda layer")
+
+				err = cleanLayer(condaLayer, effectiveCleanup, condaRunner, logger)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				logger.Break()
+			} else if !cleanup.Empty() {
+				logger.Process("Skipping cleanup of %s as conda is required at build", condaLayer.Path)
+				logger.Break()
+			}
+
+			condaLayer.Metadata = record.Metadata()
+			condaLayer.Metadata[CondaVersionKey] = info.CondaVersion
+			condaLayer.Metadata[PlatformKey] = info.Platform
+
+			if info.PythonVersion != "" {
+				condaLayer.Metadata[PythonVersionKey] = info.PythonVersion
+			}
+
+			logger.GeneratingSBOM(condaLayer.Path)
+			var sbomContent sbom.SBOM
+			duration, err = clock.Measure(func() error {
+				sbomContent, err = sbomGenerator.GenerateFromDependency(dependency, condaLayer.Path)
+				return err
 			})
 			if err != nil {
 				return packit.BuildResult{}, err
 			}
 
-			logger.Action("Configuration completed in %s", duration.Round(time.Millisecond))
+			logger.Action("Completed in %s", duration.Round(time.Millisecond))
 			logger.Break()
 
+			logger.FormattingSBOM(context.BuildpackInfo.SBOMFormats...)
+			condaLayer.SBOM, err = sbomContent.InFormats(context.BuildpackInfo.SBOMFormats...)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
 		}
 
-		condaLayer.Metadata = map[string]interface{}{
-			DepKey: dependencyChecksum,
-		}
+		setCondaEnvironment(&condaLayer, condarcChecksum != "")
 
-		logger.GeneratingSBOM(condaLayer.Path)
-		var sbomContent sbom.SBOM
-		duration, err = clock.Measure(func() error {
-			sbomContent, err = sbomGenerator.GenerateFromDependency(dependency, condaLayer.Path)
-			return err
-		})
-		if err != nil {
-			return packit.BuildResult{}, err
+		layers := []packit.Layer{condaLayer}
+
+		if envInstall {
+			// The lifecycle provides the architecture of the image that is
+			// built, which is the architecture of the build otherwise.
+			arch := GetEnvOrDefault("CNB_TARGET_ARCH", runtime.GOARCH)
+
+			envLayer, err := installEnvironment(context, executor, condaLayer, arch, logger, clock)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			err = redactSecrets(envLayer.Path, secrets)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			// Without the package cache, conda keeps the packages of the
+			// environment in the pkgs directory of the conda layer, which may
+			// be reused from a previous build.
+			if !cachePackages {
+				err = redactSecrets(condaLayer.Path, secrets)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+			}
+
+			layers = append(layers, envLayer)
 		}
 
-		logger.Action("Completed in %s", duration.Round(time.Millisecond))
-		logger.Break()
+		if cachePackages {
+			err = redactPackageCache(packageCache.Layer.Path, secrets)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
 
-		logger.FormattingSBOM(context.BuildpackInfo.SBOMFormats...)
-		condaLayer.SBOM, err = sbomContent.InFormats(context.BuildpackInfo.SBOMFormats...)
-		if err != nil {
-			return packit.BuildResult{}, err
+			err = packageCache.Prune(logger)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			layers = append(layers, packageCache.Layer)
 		}
 
 		return packit.BuildResult{
-			Layers: []packit.Layer{condaLayer},
+			Layers: layers,
 			Build:  buildMetadata,
 			Launch: launchMetadata,
 		}, nil
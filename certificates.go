@@ -0,0 +1,137 @@
+package miniconda
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+)
+
+// CACertificatesBindingType is the type of the service bindings that provide
+// additional CA certificates, with one PEM encoded certificate per entry.
+const CACertificatesBindingType = "ca-certificates"
+
+// systemCABundles is the list of locations of the CA bundle of the stack, in
+// order of preference.
+var systemCABundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/cert.pem",
+}
+
+// CACertificates holds the CA certificates that conda should trust when
+// making HTTPS requests during the build.
+type CACertificates struct {
+	// Path is the CA bundle given by $BP_CONDA_SSL_VERIFY.
+	Path string
+
+	// Certificates holds the PEM encoded certificates of the ca-certificates
+	// bindings.
+	Certificates []byte
+
+	// Sources lists where the certificates came from.
+	Sources []string
+}
+
+// resolveCACertificates returns the CA bundle given by $BP_CONDA_SSL_VERIFY
+// and the certificates of every ca-certificates binding.
+func resolveCACertificates(platformPath string, bindingResolver BindingResolver) (CACertificates, error) {
+	var certificates CACertificates
+
+	if path := GetEnvOrDefault("BP_CONDA_SSL_VERIFY", ""); path != "" {
+		exists, err := fs.Exists(path)
+		if err != nil {
+			return CACertificates{}, err
+		}
+
+		if !exists {
+			return CACertificates{}, fmt.Errorf("failed to find CA bundle %s given by $BP_CONDA_SSL_VERIFY", path)
+		}
+
+		certificates.Path = path
+		certificates.Sources = append(certificates.Sources, "$BP_CONDA_SSL_VERIFY")
+	}
+
+	bindings, err := bindingResolver.Resolve(CACertificatesBindingType, "", platformPath)
+	if err != nil {
+		return CACertificates{}, err
+	}
+
+	for _, binding := range bindings {
+		var names []string
+		for name := range binding.Entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			content, err := binding.Entries[name].ReadBytes()
+			if err != nil {
+				return CACertificates{}, err
+			}
+
+			if !bytes.Contains(content, []byte("-----BEGIN CERTIFICATE-----")) {
+				return CACertificates{}, fmt.Errorf("entry '%s' of binding '%s' is not a PEM encoded certificate", name, binding.Name)
+			}
+
+			certificates.Certificates = append(certificates.Certificates, bytes.TrimSpace(content)...)
+			certificates.Certificates = append(certificates.Certificates, '\n')
+		}
+
+		certificates.Sources = append(certificates.Sources, fmt.Sprintf("binding '%s'", binding.Name))
+	}
+
+	return certificates, nil
+}
+
+// Empty returns true when no CA certificates were configured.
+func (c CACertificates) Empty() bool {
+	return c.Path == "" && len(c.Certificates) == 0
+}
+
+// Bundle returns the path of the CA bundle to be used by conda. When there
+// are no certificates from bindings this is the bundle given by
+// $BP_CONDA_SSL_VERIFY. Otherwise a bundle is written into dir that holds the
+// certificates of the bindings in addition to those of $BP_CONDA_SSL_VERIFY,
+// or of the stack when that is not set, so that public channels keep working.
+func (c CACertificates) Bundle(dir string) (string, error) {
+	if len(c.Certificates) == 0 {
+		return c.Path, nil
+	}
+
+	base := c.Path
+	if base == "" {
+		for _, path := range systemCABundles {
+			exists, err := fs.Exists(path)
+			if err != nil {
+				return "", err
+			}
+
+			if exists {
+				base = path
+				break
+			}
+		}
+	}
+
+	var bundle []byte
+	if base != "" {
+		content, err := os.ReadFile(base)
+		if err != nil {
+			return "", fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		bundle = append(bytes.TrimSpace(content), '\n')
+	}
+
+	path := filepath.Join(dir, "ca-certificates.pem")
+	err := os.WriteFile(path, append(bundle, c.Certificates...), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -0,0 +1,179 @@
+package miniconda
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// CleanMode selects what `conda clean` removes from the conda layer.
+type CleanMode string
+
+const (
+	// CleanAll removes the index cache, tarballs, unused packages and logs.
+	CleanAll CleanMode = "all"
+
+	// CleanPackages removes tarballs and unused packages.
+	CleanPackages CleanMode = "packages"
+
+	// CleanNone does not run `conda clean`.
+	CleanNone CleanMode = "none"
+)
+
+// ParseCleanMode converts a user provided value into a CleanMode. An empty
+// value disables cleaning.
+func ParseCleanMode(value string) (CleanMode, error) {
+	switch mode := CleanMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return CleanNone, nil
+	case CleanAll, CleanPackages, CleanNone:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported clean mode %q: must be one of all, packages or none", value)
+	}
+}
+
+// Cleanup describes how the conda layer is slimmed down after installation.
+type Cleanup struct {
+	Mode CleanMode
+
+	// Patterns are globs of files and directories to remove. Patterns that
+	// contain a slash are matched against the path relative to the layer,
+	// others against the name of every file and directory in the layer.
+	Patterns []string
+}
+
+// lookupCleanup reads the cleanup configuration from $BP_CONDA_CLEAN and
+// $BP_CONDA_CLEAN_PATTERNS.
+func lookupCleanup(config Config) (Cleanup, error) {
+	mode, err := ParseCleanMode(config.Lookup("BP_CONDA_CLEAN", ""))
+	if err != nil {
+		return Cleanup{}, fmt.Errorf("failed to parse %s: %w", config.Name("BP_CONDA_CLEAN"), err)
+	}
+
+	cleanup := Cleanup{Mode: mode}
+	for _, pattern := range strings.Split(config.Lookup("BP_CONDA_CLEAN_PATTERNS", ""), ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		_, err := filepath.Match(pattern, "")
+		if err != nil {
+			return Cleanup{}, fmt.Errorf("failed to parse %s: invalid pattern %q: %w", config.Name("BP_CONDA_CLEAN_PATTERNS"), pattern, err)
+		}
+
+		cleanup.Patterns = append(cleanup.Patterns, pattern)
+	}
+
+	return cleanup, nil
+}
+
+// Empty returns true when the cleanup does nothing.
+func (c Cleanup) Empty() bool {
+	return c.Mode == CleanNone && len(c.Patterns) == 0
+}
+
+// String returns a stable representation of the cleanup that is recorded in
+// the layer metadata.
+func (c Cleanup) String() string {
+	if len(c.Patterns) == 0 {
+		return string(c.Mode)
+	}
+
+	return fmt.Sprintf("%s:%s", c.Mode, strings.Join(c.Patterns, ","))
+}
+
+// cleanLayer runs `conda clean` in the given conda layer and removes the
+// files and directories that match the patterns of the cleanup.
+func cleanLayer(layer packit.Layer, cleanup Cleanup, condaRunner CondaRunner, logger scribe.Emitter) error {
+	before, err := directorySize(layer.Path)
+	if err != nil {
+		return err
+	}
+
+	if cleanup.Mode != CleanNone {
+		args := []string{"clean", "--all", "--yes"}
+		if cleanup.Mode == CleanPackages {
+			args = []string{"clean", "--packages", "--tarballs", "--yes"}
+		}
+
+		logger.Subprocess("Running 'conda %s'", strings.Join(args, " "))
+
+		// The package cache of the build is not part of the layer and is
+		// left untouched.
+		err = condaRunner.Execute(layer.Path, pexec.Execution{
+			Args: args,
+			Env:  []string{fmt.Sprintf("CONDA_PKGS_DIRS=%s", filepath.Join(layer.Path, "pkgs"))},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(cleanup.Patterns) > 0 {
+		logger.Subprocess("Removing files matching %s", strings.Join(cleanup.Patterns, ", "))
+
+		err = removeMatching(layer.Path, cleanup.Patterns)
+		if err != nil {
+			return fmt.Errorf("failed to clean %s: %w", layer.Path, err)
+		}
+	}
+
+	after, err := directorySize(layer.Path)
+	if err != nil {
+		return err
+	}
+
+	logger.Action("Removed %s", formatBytes(before-after))
+
+	return nil
+}
+
+func removeMatching(root string, patterns []string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		for _, pattern := range patterns {
+			name := entry.Name()
+			if strings.Contains(pattern, "/") {
+				name = rel
+			}
+
+			if matched, _ := filepath.Match(pattern, name); matched {
+				err = os.RemoveAll(path)
+				if err != nil {
+					return err
+				}
+
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
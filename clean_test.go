@@ -0,0 +1,46 @@
+package miniconda_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testClean(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseCleanMode", func() {
+		it("parses the supported clean modes", func() {
+			for value, expected := range map[string]miniconda.CleanMode{
+				"":           miniconda.CleanNone,
+				"none":       miniconda.CleanNone,
+				"all":        miniconda.CleanAll,
+				"packages":   miniconda.CleanPackages,
+				" Packages ": miniconda.CleanPackages,
+			} {
+				mode, err := miniconda.ParseCleanMode(value)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mode).To(Equal(expected), value)
+			}
+		})
+
+		context("failure cases", func() {
+			context("when the clean mode is not recognized", func() {
+				it("returns an error", func() {
+					_, err := miniconda.ParseCleanMode("tarballs")
+					Expect(err).To(MatchError(`unsupported clean mode "tarballs": must be one of all, packages or none`))
+				})
+			})
+		})
+	})
+
+	context("Cleanup", func() {
+		it("has a stable string representation", func() {
+			Expect(miniconda.Cleanup{Mode: miniconda.CleanNone}.String()).To(Equal("none"))
+			Expect(miniconda.Cleanup{Mode: miniconda.CleanAll, Patterns: []string{"__pycache__", "share/doc"}}.String()).To(Equal("all:__pycache__,share/doc"))
+		})
+	})
+}
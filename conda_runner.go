@@ -0,0 +1,58 @@
+package miniconda
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// CondaExecutor implements the CondaRunner interface
+type CondaExecutor struct {
+	logger scribe.Emitter
+}
+
+// NewCondaExecutor creates an instance of the CondaExecutor that writes the
+// output of conda to the debug log of the given logger.
+func NewCondaExecutor(logger scribe.Emitter) CondaExecutor {
+	return CondaExecutor{
+		logger: logger,
+	}
+}
+
+// Execute invokes the conda executable from the bin directory of the layer
+// located at condaLayerPath. The environment of the execution is added to the
+// environment of the current process. When the execution has no output
+// writers of its own, the output of conda is included in the returned error
+// should the command fail.
+func (c CondaExecutor) Execute(condaLayerPath string, execution pexec.Execution) error {
+	// Stdout and stderr share a single writer, which os/exec does not write
+	// to concurrently.
+	buffer := bytes.NewBuffer(nil)
+	output := io.MultiWriter(buffer, c.logger.Debug.ActionWriter)
+	if execution.Stdout == nil {
+		execution.Stdout = output
+	}
+
+	if execution.Stderr == nil {
+		execution.Stderr = output
+	}
+
+	execution.Env = append(os.Environ(), execution.Env...)
+
+	err := pexec.NewExecutable(filepath.Join(condaLayerPath, "bin", "conda")).Execute(execution)
+	if err != nil {
+		if output := strings.TrimSpace(buffer.String()); output != "" {
+			return fmt.Errorf("failed to run conda %s: %w\n%s", strings.Join(execution.Args, " "), err, output)
+		}
+
+		return fmt.Errorf("failed to run conda %s: %w", strings.Join(execution.Args, " "), err)
+	}
+
+	return nil
+}
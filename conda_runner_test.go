@@ -0,0 +1,98 @@
+package miniconda_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCondaExecutor(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath string
+		buffer    *bytes.Buffer
+
+		executor miniconda.CondaExecutor
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "conda-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(layerPath, "bin"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layerPath, "bin", "conda"), []byte(`#!/bin/sh
+echo "args: $*"
+echo "some-var: $SOME_VAR"
+if [ "$1" = "fail" ]; then
+  echo "something went wrong" >&2
+  exit 1
+fi
+`), 0755)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+		executor = miniconda.NewCondaExecutor(scribe.NewEmitter(buffer).WithLevel("DEBUG"))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	context("Execute", func() {
+		it("runs conda from the layer with the given arguments and environment", func() {
+			stdout := bytes.NewBuffer(nil)
+			err := executor.Execute(layerPath, pexec.Execution{
+				Args:   []string{"info", "--json"},
+				Env:    []string{"SOME_VAR=some-value"},
+				Stdout: stdout,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(ContainSubstring("args: info --json"))
+			Expect(stdout.String()).To(ContainSubstring("some-var: some-value"))
+		})
+
+		it("writes the output to the debug log when no writer is given", func() {
+			err := executor.Execute(layerPath, pexec.Execution{
+				Args: []string{"install"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("args: install"))
+		})
+
+		context("failure cases", func() {
+			context("when conda fails", func() {
+				it("returns an error that includes the output", func() {
+					err := executor.Execute(layerPath, pexec.Execution{
+						Args: []string{"fail", "now"},
+					})
+					Expect(err).To(MatchError(ContainSubstring("failed to run conda fail now: exit status 1")))
+					Expect(err).To(MatchError(ContainSubstring("something went wrong")))
+				})
+			})
+
+			context("when conda is not installed in the layer", func() {
+				it.Before(func() {
+					Expect(os.Remove(filepath.Join(layerPath, "bin", "conda"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := executor.Execute(layerPath, pexec.Execution{
+						Args: []string{"info"},
+					})
+					Expect(err).To(MatchError(ContainSubstring("failed to run conda info")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,252 @@
+package miniconda
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
+	"gopkg.in/yaml.v3"
+)
+
+// CondarcBindingType is the type of the service binding that can provide a
+// .condarc file to be merged into the generated conda configuration.
+const CondarcBindingType = "condarc"
+
+// Condarc is the conda configuration that is written into the root of the
+// conda layer, where conda reads it as part of its search path.
+type Condarc struct {
+	// Config holds the merged configuration values.
+	Config map[string]interface{}
+
+	// Sources lists where the configuration values came from, in the order
+	// that they were merged.
+	Sources []string
+}
+
+// generateCondarc merges, in increasing order of precedence, the .condarc
+// file of the application, the .condarc entry of a condarc service binding,
+// $BP_CONDA_CHANNELS, $BP_CONDA_CHANNEL_PRIORITY and the configured solver.
+// The channels and the channel priority may also be configured in
+// project.toml.
+func generateCondarc(workingDir, platformPath string, bindingResolver BindingResolver, solver Solver, config Config) (Condarc, error) {
+	condarc := Condarc{Config: map[string]interface{}{}}
+
+	content, err := os.ReadFile(filepath.Join(workingDir, ".condarc"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Condarc{}, fmt.Errorf("failed to read .condarc: %w", err)
+	}
+
+	if err == nil {
+		err = condarc.merge(content)
+		if err != nil {
+			return Condarc{}, fmt.Errorf("failed to parse .condarc: %w", err)
+		}
+		condarc.Sources = append(condarc.Sources, ".condarc")
+	}
+
+	bindings, err := bindingResolver.Resolve(CondarcBindingType, "", platformPath)
+	if err != nil {
+		return Condarc{}, err
+	}
+
+	if len(bindings) > 1 {
+		return Condarc{}, fmt.Errorf("binding resolver found more than one binding of type '%s'", CondarcBindingType)
+	}
+
+	if len(bindings) == 1 {
+		entry, err := condarcEntry(bindings[0])
+		if err != nil {
+			return Condarc{}, err
+		}
+
+		content, err := entry.ReadBytes()
+		if err != nil {
+			return Condarc{}, err
+		}
+
+		err = condarc.merge(content)
+		if err != nil {
+			return Condarc{}, fmt.Errorf("failed to parse .condarc from binding '%s': %w", bindings[0].Name, err)
+		}
+		condarc.Sources = append(condarc.Sources, fmt.Sprintf("binding '%s'", bindings[0].Name))
+	}
+
+	if value := config.Lookup("BP_CONDA_CHANNELS", ""); value != "" {
+		var channels []string
+		for _, channel := range strings.Split(value, ",") {
+			if channel = strings.TrimSpace(channel); channel != "" {
+				channels = append(channels, channel)
+			}
+		}
+
+		condarc.Config["channels"] = channels
+		condarc.Sources = append(condarc.Sources, config.Name("BP_CONDA_CHANNELS"))
+	}
+
+	if value := config.Lookup("BP_CONDA_CHANNEL_PRIORITY", ""); value != "" {
+		switch value {
+		case "strict", "flexible", "disabled":
+			condarc.Config["channel_priority"] = value
+		default:
+			return Condarc{}, fmt.Errorf("failed to parse %s: unsupported channel priority %q: must be one of strict, flexible or disabled", config.Name("BP_CONDA_CHANNEL_PRIORITY"), value)
+		}
+		condarc.Sources = append(condarc.Sources, config.Name("BP_CONDA_CHANNEL_PRIORITY"))
+	}
+
+	// The classic solver is left to the default of the installed conda.
+	if solver == LibmambaSolver {
+		condarc.Config["solver"] = string(solver)
+		condarc.Sources = append(condarc.Sources, "solver")
+	}
+
+	return condarc, nil
+}
+
+// Empty returns true when there is no configuration to write.
+func (c Condarc) Empty() bool {
+	return len(c.Config) == 0
+}
+
+// Marshal renders the configuration as YAML. The keys are sorted, so the
+// output is stable for a given configuration.
+func (c Condarc) Marshal() ([]byte, error) {
+	if c.Empty() {
+		return nil, nil
+	}
+
+	return yaml.Marshal(c.Config)
+}
+
+// Checksum returns the SHA256 of the rendered configuration, or an empty
+// string when there is no configuration to write.
+func (c Condarc) Checksum() (string, error) {
+	content, err := c.Marshal()
+	if err != nil || len(content) == 0 {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// merge overrides the current configuration with the given YAML content.
+// Mappings such as custom_channels are merged key by key, while all other
+// values are replaced.
+func (c *Condarc) merge(content []byte) error {
+	var config map[string]interface{}
+	err := yaml.Unmarshal(content, &config)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range config {
+		if values, ok := value.(map[string]interface{}); ok {
+			if existing, ok := c.Config[key].(map[string]interface{}); ok {
+				for k, v := range values {
+					existing[k] = v
+				}
+				continue
+			}
+		}
+
+		c.Config[key] = value
+	}
+
+	return nil
+}
+
+func condarcEntry(binding servicebindings.Binding) (*servicebindings.Entry, error) {
+	for _, name := range []string{".condarc", "condarc"} {
+		if entry, ok := binding.Entries[name]; ok {
+			return entry, nil
+		}
+	}
+
+	return nil, fmt.Errorf("binding of type '%s' is missing required entry '.condarc'", CondarcBindingType)
+}
+
+// configureBuildConda writes the conda configuration that is only used during
+// the build, holding the credentials of private channels and the CA bundle,
+// into a layer that has no type set to true, so that the lifecycle will ensure
+// that it is removed. It returns a CondaRunner that passes this configuration
+// to every execution of conda, along with the secrets that must be redacted
+// from the layers written by conda.
+func configureBuildConda(context packit.BuildContext, condaRunner CondaRunner, bindingResolver BindingResolver, logger scribe.Emitter) (CondaRunner, []string, error) {
+	auths, err := resolveChannelAuth(context.Platform.Path, bindingResolver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certificates, err := resolveCACertificates(context.Platform.Path, bindingResolver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(auths) == 0 && certificates.Empty() {
+		return condaRunner, nil, nil
+	}
+
+	layer, err := context.Layers.Get("conda-build-temp-layer")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	layer, err = layer.Reset()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := map[string]interface{}{}
+	var env, secrets []string
+
+	if len(auths) > 0 {
+		channels, err := authenticatedChannels(auths)
+		if err != nil {
+			return nil, nil, err
+		}
+		config["channels"] = channels
+
+		logger.Process("Configuring credentials for private conda channels")
+		for _, auth := range auths {
+			logger.Subprocess("%s (binding '%s')", auth.Channel, auth.Binding)
+			secrets = append(secrets, auth.Secrets()...)
+		}
+		logger.Break()
+	}
+
+	if !certificates.Empty() {
+		bundle, err := certificates.Bundle(layer.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		config["ssl_verify"] = bundle
+		env = append(env, fmt.Sprintf("REQUESTS_CA_BUNDLE=%s", bundle), fmt.Sprintf("SSL_CERT_FILE=%s", bundle))
+
+		logger.Process("Configuring CA certificates for conda")
+		logger.Subprocess("Using certificates from: %s", strings.Join(certificates.Sources, ", "))
+		logger.Break()
+	}
+
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// $CONDARC has the highest precedence of all the files that conda reads
+	// its configuration from.
+	path := filepath.Join(layer.Path, ".condarc")
+	err = os.WriteFile(path, content, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+	env = append(env, fmt.Sprintf("CONDARC=%s", path))
+
+	return condaEnvRunner{runner: condaRunner, env: env}, secrets, nil
+}
@@ -0,0 +1,218 @@
+package miniconda
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// ProjectFile is the project descriptor of the application, whose
+// [com.paketo.miniconda] table configures the buildpack.
+const ProjectFile = "project.toml"
+
+// ConfigKey is a setting of the buildpack, which is read from its environment
+// variable or, when that is not set, from its key in the
+// [com.paketo.miniconda] table of project.toml.
+type ConfigKey struct {
+	// Env is the name of the environment variable.
+	Env string
+
+	// Project is the key in the project.toml table.
+	Project string
+
+	// Separator joins the items when the key is a list in project.toml, in
+	// the same way that they are separated in the environment variable. Keys
+	// without a separator only accept a string.
+	Separator string
+}
+
+// ConfigKeys is the list of settings that can be configured in project.toml.
+var ConfigKeys = []ConfigKey{
+	{Env: "BP_MINICONDA_VERSION", Project: "version"},
+	{Env: "BP_CONDA_DISTRIBUTION", Project: "distribution"},
+	{Env: "BP_MINICONDA_SOLVER", Project: "solver"},
+	{Env: "BP_CONDA_CHANNELS", Project: "channels", Separator: ","},
+	{Env: "BP_CONDA_CHANNEL_PRIORITY", Project: "channel-priority"},
+	{Env: "BP_CONDA_BASE_PACKAGES", Project: "base-packages", Separator: " "},
+	{Env: "BP_CONDA_CLEAN", Project: "clean"},
+	{Env: "BP_CONDA_CLEAN_PATTERNS", Project: "clean-patterns", Separator: ","},
+}
+
+// Config is the configuration of the buildpack. Settings are taken, in
+// decreasing order of precedence, from their environment variable, from
+// project.toml and from their default.
+type Config struct {
+	project map[string]string
+}
+
+// LoadConfig reads the [com.paketo.miniconda] table of the project.toml in
+// the working directory, if there is one.
+func LoadConfig(workingDir string) (Config, error) {
+	config := Config{project: map[string]string{}}
+
+	var project struct {
+		Com struct {
+			Paketo struct {
+				Miniconda map[string]interface{} `toml:"miniconda"`
+			} `toml:"paketo"`
+		} `toml:"com"`
+	}
+
+	_, err := toml.DecodeFile(filepath.Join(workingDir, ProjectFile), &project)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+
+		return Config{}, fmt.Errorf("failed to parse %s: %w", ProjectFile, err)
+	}
+
+	for name, value := range project.Com.Paketo.Miniconda {
+		key, found := projectConfigKey(name)
+		if !found {
+			var names []string
+			for _, k := range ConfigKeys {
+				names = append(names, k.Project)
+			}
+
+			return Config{}, fmt.Errorf("failed to parse %s: unsupported key %q in [com.paketo.miniconda]: must be one of %s", ProjectFile, name, strings.Join(names, ", "))
+		}
+
+		switch v := value.(type) {
+		case string:
+			config.project[key.Env] = v
+		case []interface{}:
+			items, ok := stringItems(v)
+			if !ok || key.Separator == "" {
+				return Config{}, fmt.Errorf("failed to parse %s: %s in [com.paketo.miniconda] must be %s", ProjectFile, name, key.kind())
+			}
+
+			config.project[key.Env] = strings.Join(items, key.Separator)
+		default:
+			return Config{}, fmt.Errorf("failed to parse %s: %s in [com.paketo.miniconda] must be %s", ProjectFile, name, key.kind())
+		}
+	}
+
+	return config, nil
+}
+
+func projectConfigKey(name string) (ConfigKey, bool) {
+	for _, key := range ConfigKeys {
+		if key.Project == name {
+			return key, true
+		}
+	}
+
+	return ConfigKey{}, false
+}
+
+func stringItems(values []interface{}) ([]string, bool) {
+	var items []string
+	for _, value := range values {
+		item, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+
+		items = append(items, item)
+	}
+
+	return items, true
+}
+
+func (k ConfigKey) kind() string {
+	if k.Separator == "" {
+		return "a string"
+	}
+
+	return "a string or a list of strings"
+}
+
+// Lookup returns the value of the setting with the given environment
+// variable, or defaultValue when it is neither set in the environment nor in
+// project.toml.
+func (c Config) Lookup(env, defaultValue string) string {
+	if value, ok := os.LookupEnv(env); ok {
+		return value
+	}
+
+	if value, ok := c.project[env]; ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+// Source returns where the setting with the given environment variable is
+// configured: the name of the environment variable, ProjectFile, or nothing
+// when the default applies.
+func (c Config) Source(env string) string {
+	if _, ok := os.LookupEnv(env); ok {
+		return env
+	}
+
+	if _, ok := c.project[env]; ok {
+		return ProjectFile
+	}
+
+	return ""
+}
+
+// Name returns how the setting with the given environment variable is
+// referred to in error messages, which is the project.toml key when the
+// setting is only configured there.
+func (c Config) Name(env string) string {
+	if c.Source(env) == ProjectFile {
+		key, _ := envConfigKey(env)
+		return fmt.Sprintf("%s in %s", key.Project, ProjectFile)
+	}
+
+	return "$" + env
+}
+
+func envConfigKey(env string) (ConfigKey, bool) {
+	for _, key := range ConfigKeys {
+		if key.Env == env {
+			return key, true
+		}
+	}
+
+	return ConfigKey{}, false
+}
+
+// summary logs the settings that are configured, along with where they are
+// configured.
+func (c Config) summary(logger scribe.Emitter) {
+	var width int
+	for _, key := range ConfigKeys {
+		if c.Source(key.Env) != "" && len(key.Env) > width {
+			width = len(key.Env)
+		}
+	}
+
+	logger.Process("Configuration")
+	if width == 0 {
+		logger.Subprocess("Using the default configuration")
+		logger.Break()
+		return
+	}
+
+	for _, key := range ConfigKeys {
+		source := c.Source(key.Env)
+		if source == "" {
+			continue
+		}
+
+		if source == key.Env {
+			source = "environment"
+		}
+
+		logger.Subprocess("%-*s -> %q (%s)", width, key.Env, c.Lookup(key.Env, ""), source)
+	}
+	logger.Break()
+}
@@ -0,0 +1,150 @@
+package miniconda_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testConfig(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+	)
+
+	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(workingDir, "project.toml"), []byte(`[_]
+schema-version = "0.2"
+
+[com.paketo.miniconda]
+version = "24.1.*"
+solver = "libmamba"
+channels = ["conda-forge", "bioconda"]
+base-packages = ["conda-build", "pip>=23,<24"]
+clean = "all"
+clean-patterns = "__pycache__"
+`), 0600)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
+	context("LoadConfig", func() {
+		it("reads the settings from project.toml", func() {
+			config, err := miniconda.LoadConfig(workingDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(config.Lookup("BP_MINICONDA_VERSION", "*")).To(Equal("24.1.*"))
+			Expect(config.Lookup("BP_MINICONDA_SOLVER", "")).To(Equal("libmamba"))
+			Expect(config.Lookup("BP_CONDA_CHANNELS", "")).To(Equal("conda-forge,bioconda"))
+			Expect(config.Lookup("BP_CONDA_BASE_PACKAGES", "")).To(Equal("conda-build pip>=23,<24"))
+			Expect(config.Lookup("BP_CONDA_CLEAN", "")).To(Equal("all"))
+			Expect(config.Lookup("BP_CONDA_CLEAN_PATTERNS", "")).To(Equal("__pycache__"))
+			Expect(config.Lookup("BP_CONDA_DISTRIBUTION", "miniconda")).To(Equal("miniconda"))
+
+			Expect(config.Source("BP_MINICONDA_SOLVER")).To(Equal("project.toml"))
+			Expect(config.Source("BP_CONDA_DISTRIBUTION")).To(BeEmpty())
+			Expect(config.Name("BP_CONDA_CHANNELS")).To(Equal("channels in project.toml"))
+			Expect(config.Name("BP_CONDA_DISTRIBUTION")).To(Equal("$BP_CONDA_DISTRIBUTION"))
+		})
+
+		context("when the environment variable is set", func() {
+			it.Before(func() {
+				t.Setenv("BP_MINICONDA_SOLVER", "classic")
+			})
+
+			it("takes precedence over project.toml", func() {
+				config, err := miniconda.LoadConfig(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(config.Lookup("BP_MINICONDA_SOLVER", "")).To(Equal("classic"))
+				Expect(config.Source("BP_MINICONDA_SOLVER")).To(Equal("BP_MINICONDA_SOLVER"))
+				Expect(config.Name("BP_MINICONDA_SOLVER")).To(Equal("$BP_MINICONDA_SOLVER"))
+			})
+		})
+
+		context("when there is no project.toml", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workingDir, "project.toml"))).To(Succeed())
+			})
+
+			it("returns the defaults", func() {
+				config, err := miniconda.LoadConfig(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(config.Lookup("BP_MINICONDA_SOLVER", "classic")).To(Equal("classic"))
+				Expect(config.Source("BP_MINICONDA_SOLVER")).To(BeEmpty())
+			})
+		})
+
+		context("when project.toml has no table for the buildpack", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "project.toml"), []byte("[io.buildpacks]\nexclude = [\"*.md\"]\n"), 0600)).To(Succeed())
+			})
+
+			it("returns the defaults", func() {
+				config, err := miniconda.LoadConfig(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(config.Lookup("BP_CONDA_CHANNELS", "")).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when project.toml cannot be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "project.toml"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := miniconda.LoadConfig(workingDir)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse project.toml")))
+				})
+			})
+
+			context("when the table has an unsupported key", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "project.toml"), []byte("[com.paketo.miniconda]\nsolvr = \"libmamba\"\n"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := miniconda.LoadConfig(workingDir)
+					Expect(err).To(MatchError(`failed to parse project.toml: unsupported key "solvr" in [com.paketo.miniconda]: must be one of version, distribution, solver, channels, channel-priority, base-packages, clean, clean-patterns`))
+				})
+			})
+
+			context("when a string key is a list", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "project.toml"), []byte("[com.paketo.miniconda]\nsolver = [\"libmamba\"]\n"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := miniconda.LoadConfig(workingDir)
+					Expect(err).To(MatchError("failed to parse project.toml: solver in [com.paketo.miniconda] must be a string"))
+				})
+			})
+
+			context("when a list key is not a list of strings", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "project.toml"), []byte("[com.paketo.miniconda]\nchannels = [1, 2]\n"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := miniconda.LoadConfig(workingDir)
+					Expect(err).To(MatchError("failed to parse project.toml: channels in [com.paketo.miniconda] must be a string or a list of strings"))
+				})
+			})
+		})
+	})
+}
@@ -5,4 +5,82 @@ const (
 	// download in the layer metadata, which is used to determine if the conda
 	// layer can be resued on during a rebuild.
 	DepKey = "dependency-sha"
+
+	// This is the key name that we use to store the distribution that was
+	// installed in the conda layer, which is used alongside DepKey to
+	// determine if the conda layer can be reused during a rebuild.
+	DistributionKey = "distribution"
+
+	// This is the key name that we use to store the solver that was configured
+	// in the conda layer, which is used alongside DepKey to determine if the
+	// conda layer can be reused during a rebuild.
+	SolverKey = "solver"
+
+	// This is the key name that we use to store the sha of the generated
+	// .condarc file, which is used alongside DepKey to determine if the conda
+	// layer can be reused during a rebuild.
+	CondarcKey = "condarc-sha"
+
+	// This is the key name that we use to store the cleanup that was applied
+	// to the conda layer, which is used alongside DepKey to determine if the
+	// conda layer can be reused during a rebuild.
+	CleanKey = "clean"
+
+	// This is the key name that we use to store the version of the buildpack
+	// that built the conda layer, which is used alongside DepKey to determine
+	// if the conda layer can be reused during a rebuild.
+	BuildpackVersionKey = "buildpack-version"
+
+	// This is the key name that we use to store the sha of the solver channel
+	// dependency that the libmamba solver is installed from, which is used
+	// alongside DepKey to determine if the conda layer can be reused during a
+	// rebuild.
+	SolverChannelKey = "solver-channel-sha"
+
+	// This is the key name that we use to store the package specs installed
+	// into the base environment of the conda layer, which is used alongside
+	// DepKey to determine if the conda layer can be reused during a rebuild.
+	BasePackagesKey = "base-packages"
+
+	// This is the key name that we use to store the fingerprint of all inputs
+	// that the conda layer was built from, as recorded by CondaLayerMetadata.
+	FingerprintKey = "fingerprint"
+
+	// This is the key name that we use to store the fingerprint of the conda
+	// layer in the conda-env layer metadata, which is used to determine if the
+	// conda-env layer can be reused during a rebuild.
+	CondaFingerprintKey = "conda-fingerprint"
+
+	// These are the key names that we use to store the conda version, python
+	// version and platform reported by the conda installation in the conda
+	// layer metadata.
+	CondaVersionKey  = "conda-version"
+	PythonVersionKey = "python-version"
+	PlatformKey      = "platform"
+
+	// This is the key name that we use to store the sha of the environment
+	// file in the conda-env layer metadata, which is used to determine if the
+	// conda-env layer can be reused during a rebuild.
+	EnvironmentKey = "environment-sha"
+
+	// This is the key name that we use to store the sha of the lock file in
+	// the conda-env layer metadata, which is used alongside the platform to
+	// determine if the conda-env layer can be reused during a rebuild.
+	LockKey = "lock-sha"
+
+	// This is the key name that we use to store the sha of the indexes of the
+	// vendored channel in the conda-env layer metadata, which is used
+	// alongside EnvironmentKey to determine if the conda-env layer can be
+	// reused during a rebuild.
+	VendorKey = "vendor-sha"
 )
+
+// Priorities is the list of version-sources that are considered when picking
+// the conda version from the Build Plan entries, in order from highest to
+// lowest priority. Entries with a version-source that is not listed, or with
+// no version-source at all, have the lowest priority.
+var Priorities = []interface{}{
+	"BP_MINICONDA_VERSION",
+	"project.toml",
+	".miniconda-version",
+}
@@ -1,19 +1,95 @@
 package miniconda
 
-import "github.com/paketo-buildpacks/packit/v2"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/fs"
+)
+
+// ProjectFiles is the list of files that indicate that the working directory
+// contains a conda project.
+var ProjectFiles = []string{
+	"environment.yml",
+	"environment.yaml",
+	"conda-lock.yml",
+	"package-list.txt",
+	".condarc",
+}
+
+// BuildPlanMetadata is the buildpack specific data included in the conda
+// Build Plan requirement.
+type BuildPlanMetadata struct {
+	Version       string `toml:"version,omitempty"`
+	VersionSource string `toml:"version-source,omitempty"`
+	Launch        bool   `toml:"launch"`
+}
 
 // Detect will return a packit.DetectFunc that will be invoked during the
 // detect phase of the buildpack lifecycle.
 //
-// Detection always passes, and will contribute a  Build Plan that provides conda.
+// Detection always passes, and will contribute a Build Plan that provides
+// conda. When the working directory contains a conda project file, the Build
+// Plan will also require conda at launch, using the version given by
+// $BP_MINICONDA_VERSION, the version in project.toml or the .miniconda-version
+// file when present.
 func Detect() packit.DetectFunc {
 	return func(context packit.DetectContext) (packit.DetectResult, error) {
-		return packit.DetectResult{
+		result := packit.DetectResult{
 			Plan: packit.BuildPlan{
 				Provides: []packit.BuildPlanProvision{
 					{Name: "conda"},
 				},
 			},
-		}, nil
+		}
+
+		var found bool
+		for _, file := range ProjectFiles {
+			exists, err := fs.Exists(filepath.Join(context.WorkingDir, file))
+			if err != nil {
+				return packit.DetectResult{}, err
+			}
+
+			if exists {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return result, nil
+		}
+
+		config, err := LoadConfig(context.WorkingDir)
+		if err != nil {
+			return packit.DetectResult{}, err
+		}
+
+		metadata := BuildPlanMetadata{Launch: true}
+		if version := config.Lookup("BP_MINICONDA_VERSION", ""); version != "" {
+			metadata.Version = version
+			metadata.VersionSource = config.Source("BP_MINICONDA_VERSION")
+		} else {
+			content, err := os.ReadFile(filepath.Join(context.WorkingDir, ".miniconda-version"))
+			if err != nil && !os.IsNotExist(err) {
+				return packit.DetectResult{}, err
+			}
+
+			if version := strings.TrimSpace(string(content)); version != "" {
+				metadata.Version = version
+				metadata.VersionSource = ".miniconda-version"
+			}
+		}
+
+		result.Plan.Requires = []packit.BuildPlanRequirement{
+			{
+				Name:     "conda",
+				Metadata: metadata,
+			},
+		}
+
+		return result, nil
 	}
 }
@@ -1,10 +1,12 @@
 package miniconda_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
-	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/sclevine/spec"
 
 	. "github.com/onsi/gomega"
@@ -14,16 +16,26 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 	var (
 		Expect = NewWithT(t).Expect
 
+		workingDir string
+
 		detect packit.DetectFunc
 	)
 
 	it.Before(func() {
+		var err error
+		workingDir, err = os.MkdirTemp("", "working-dir")
+		Expect(err).NotTo(HaveOccurred())
+
 		detect = miniconda.Detect()
 	})
 
+	it.After(func() {
+		Expect(os.RemoveAll(workingDir)).To(Succeed())
+	})
+
 	it("returns a plan that provides conda", func() {
 		result, err := detect(packit.DetectContext{
-			WorkingDir: "/working-dir",
+			WorkingDir: workingDir,
 		})
 		Expect(err).NotTo(HaveOccurred())
 		Expect(result).To(Equal(packit.DetectResult{
@@ -34,4 +46,140 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 			},
 		}))
 	})
+
+	for _, file := range miniconda.ProjectFiles {
+		file := file
+
+		context("when the working directory contains "+file, func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, file), nil, 0600)).To(Succeed())
+			})
+
+			it("returns a plan that provides and requires conda", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(packit.DetectResult{
+					Plan: packit.BuildPlan{
+						Provides: []packit.BuildPlanProvision{
+							{Name: "conda"},
+						},
+						Requires: []packit.BuildPlanRequirement{
+							{
+								Name:     "conda",
+								Metadata: miniconda.BuildPlanMetadata{Launch: true},
+							},
+						},
+					},
+				}))
+			})
+		})
+	}
+
+	context("when the working directory contains a conda project", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "environment.yml"), nil, 0600)).To(Succeed())
+		})
+
+		context("and a .miniconda-version file", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, ".miniconda-version"), []byte("24.1.*\n"), 0600)).To(Succeed())
+			})
+
+			it("requires the version from the file", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+					{
+						Name: "conda",
+						Metadata: miniconda.BuildPlanMetadata{
+							Version:       "24.1.*",
+							VersionSource: ".miniconda-version",
+							Launch:        true,
+						},
+					},
+				}))
+			})
+
+			context("and BP_MINICONDA_VERSION is set", func() {
+				it.Before(func() {
+					t.Setenv("BP_MINICONDA_VERSION", "23.*")
+				})
+
+				it("requires the version from the environment variable", func() {
+					result, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+						{
+							Name: "conda",
+							Metadata: miniconda.BuildPlanMetadata{
+								Version:       "23.*",
+								VersionSource: "BP_MINICONDA_VERSION",
+								Launch:        true,
+							},
+						},
+					}))
+				})
+			})
+		})
+
+		context("and a project.toml that configures the version", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, ".miniconda-version"), []byte("24.1.*\n"), 0600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "project.toml"), []byte(`[com.paketo.miniconda]
+version = "24.3.*"
+`), 0600)).To(Succeed())
+			})
+
+			it("requires the version from project.toml", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+					{
+						Name: "conda",
+						Metadata: miniconda.BuildPlanMetadata{
+							Version:       "24.3.*",
+							VersionSource: "project.toml",
+							Launch:        true,
+						},
+					},
+				}))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the .miniconda-version file cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Mkdir(filepath.Join(workingDir, ".miniconda-version"), os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).To(MatchError(ContainSubstring("is a directory")))
+				})
+			})
+
+			context("when project.toml cannot be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "project.toml"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).To(MatchError(ContainSubstring("failed to parse project.toml")))
+				})
+			})
+		})
+	})
 }
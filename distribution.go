@@ -0,0 +1,66 @@
+package miniconda
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Distribution is the conda installer that is installed into the conda layer.
+type Distribution string
+
+const (
+	// MinicondaDistribution is the Miniconda installer published by Anaconda,
+	// which uses the Anaconda default channels.
+	MinicondaDistribution Distribution = "miniconda"
+
+	// MiniforgeDistribution is the Miniforge installer published by
+	// conda-forge, which only uses the conda-forge channel.
+	MiniforgeDistribution Distribution = "miniforge"
+)
+
+// Distributions is the list of supported distributions, each of which must
+// have a dependency in buildpack.toml for every supported architecture.
+var Distributions = []Distribution{MinicondaDistribution, MiniforgeDistribution}
+
+// ParseDistribution converts a user provided distribution name into a
+// Distribution. The dependency ids "miniconda3" and "miniforge3" are accepted
+// as aliases, and an empty value selects Miniconda.
+func ParseDistribution(value string) (Distribution, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "miniconda", "miniconda3":
+		return MinicondaDistribution, nil
+	case "miniforge", "miniforge3":
+		return MiniforgeDistribution, nil
+	default:
+		return "", fmt.Errorf("unsupported distribution %q: must be one of miniconda or miniforge", value)
+	}
+}
+
+// lookupDistribution reads the distribution from $BP_CONDA_DISTRIBUTION.
+func lookupDistribution(config Config) (Distribution, error) {
+	distribution, err := ParseDistribution(config.Lookup("BP_CONDA_DISTRIBUTION", ""))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", config.Name("BP_CONDA_DISTRIBUTION"), err)
+	}
+
+	return distribution, nil
+}
+
+// DependencyID returns the id of the buildpack.toml dependency that provides
+// the installer of the distribution.
+func (d Distribution) DependencyID() string {
+	if d == MiniforgeDistribution {
+		return "miniforge3"
+	}
+
+	return "miniconda3"
+}
+
+// Title returns the name of the distribution as it is used in log output.
+func (d Distribution) Title() string {
+	if d == MiniforgeDistribution {
+		return "Miniforge"
+	}
+
+	return "Miniconda"
+}
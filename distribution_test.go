@@ -0,0 +1,71 @@
+package miniconda_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testDistribution(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseDistribution", func() {
+		it("parses the supported distribution names", func() {
+			for value, expected := range map[string]miniconda.Distribution{
+				"":            miniconda.MinicondaDistribution,
+				"miniconda":   miniconda.MinicondaDistribution,
+				"miniconda3":  miniconda.MinicondaDistribution,
+				"miniforge":   miniconda.MiniforgeDistribution,
+				" Miniforge3": miniconda.MiniforgeDistribution,
+			} {
+				distribution, err := miniconda.ParseDistribution(value)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(distribution).To(Equal(expected), value)
+			}
+		})
+
+		context("failure cases", func() {
+			context("when the distribution is not recognized", func() {
+				it("returns an error", func() {
+					_, err := miniconda.ParseDistribution("anaconda")
+					Expect(err).To(MatchError(`unsupported distribution "anaconda": must be one of miniconda or miniforge`))
+				})
+			})
+		})
+	})
+
+	context("DependencyID", func() {
+		it("returns the id of the buildpack.toml dependency", func() {
+			Expect(miniconda.MinicondaDistribution.DependencyID()).To(Equal("miniconda3"))
+			Expect(miniconda.MiniforgeDistribution.DependencyID()).To(Equal("miniforge3"))
+		})
+	})
+
+	context("when resolved against buildpack.toml", func() {
+		for _, distribution := range miniconda.Distributions {
+			for _, arch := range []string{"amd64", "arm64"} {
+				distribution, arch := distribution, arch
+
+				it("provides the "+string(distribution)+" dependency for "+arch, func() {
+					t.Setenv("CNB_TARGET_ARCH", arch)
+
+					dependency, err := postal.NewService(cargo.NewTransport()).Resolve("buildpack.toml", distribution.DependencyID(), "*", "some-stack")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(dependency.ID).To(Equal(distribution.DependencyID()))
+					//nolint:staticcheck // SHA256 is still used by the miniconda3 dependency
+					Expect(dependency.Checksum + dependency.SHA256).To(MatchRegexp(`^(sha256:)?[0-9a-f]{64}$`))
+					Expect(dependency.CPE).NotTo(BeEmpty())
+
+					_, err = miniconda.InstallerKindFor(dependency)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			}
+		}
+	})
+}
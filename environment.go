@@ -0,0 +1,275 @@
+package miniconda
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/chronos"
+	"github.com/paketo-buildpacks/packit/v2/fs"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// EnvironmentFiles is the list of conda environment files that can be
+// installed when $BP_CONDA_ENV_INSTALL is true, in order of preference.
+var EnvironmentFiles = []string{
+	"environment.yml",
+	"environment.yaml",
+}
+
+// installEnvironment installs the lock file or, when there is none, the
+// environment file found in the working directory into the cached conda-env
+// launch layer using the conda from the given conda layer. The layer is reused
+// when neither the file nor the conda layer, as identified by its fingerprint,
+// have changed since the last build. A lock file is only installed when it covers
+// the conda platform of the given architecture.
+func installEnvironment(
+	context packit.BuildContext,
+	condaRunner CondaRunner,
+	condaLayer packit.Layer,
+	arch string,
+	logger scribe.Emitter,
+	clock chronos.Clock,
+) (packit.Layer, error) {
+	condaFingerprint, _ := condaLayer.Metadata[FingerprintKey].(string)
+
+	platform, err := CondaPlatform(arch)
+	if err != nil {
+		return packit.Layer{}, fmt.Errorf("failed to install conda environment: %w", err)
+	}
+
+	lockFile, err := FindLockFile(context.WorkingDir, platform)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	if lockFile != "" {
+		return installLockFile(context, condaRunner, condaLayer, lockFile, platform, condaFingerprint, logger, clock)
+	}
+
+	var envFile string
+	for _, file := range EnvironmentFiles {
+		exists, err := fs.Exists(filepath.Join(context.WorkingDir, file))
+		if err != nil {
+			return packit.Layer{}, err
+		}
+
+		if exists {
+			envFile = filepath.Join(context.WorkingDir, file)
+			break
+		}
+	}
+
+	if envFile == "" {
+		return packit.Layer{}, fmt.Errorf("failed to install conda environment: none of %s found in %s", strings.Join(EnvironmentFiles, ", "), context.WorkingDir)
+	}
+
+	envChecksum, err := fs.NewChecksumCalculator().Sum(envFile)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	vendored, found, err := findVendoredChannel(context.WorkingDir)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	var vendorChecksum string
+	if found {
+		vendorChecksum, err = vendored.Checksum()
+		if err != nil {
+			return packit.Layer{}, err
+		}
+	}
+
+	envLayer, err := context.Layers.Get("conda-env")
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	cachedEnvChecksum, _ := envLayer.Metadata[EnvironmentKey].(string)
+	cachedVendorChecksum, _ := envLayer.Metadata[VendorKey].(string)
+	cachedCondaFingerprint, _ := envLayer.Metadata[CondaFingerprintKey].(string)
+	if cachedEnvChecksum == envChecksum && cachedVendorChecksum == vendorChecksum && cachedCondaFingerprint == condaFingerprint {
+		logger.Process("Reusing cached layer %s", envLayer.Path)
+		logger.Break()
+
+		envLayer.Launch, envLayer.Cache = true, true
+		setActivatedEnvironment(&envLayer)
+
+		return envLayer, nil
+	}
+
+	var specs []string
+	if found {
+		specs, err = environmentSpecs(envFile)
+		if err != nil {
+			return packit.Layer{}, fmt.Errorf("failed to install conda environment: %w", err)
+		}
+
+		missing, err := vendored.missingPackages(specs)
+		if err != nil {
+			return packit.Layer{}, fmt.Errorf("failed to install conda environment: %w", err)
+		}
+
+		if len(missing) > 0 {
+			return packit.Layer{}, fmt.Errorf("failed to install conda environment: packages of %s are missing from the vendored channel %s: %s", filepath.Base(envFile), vendored.Path, strings.Join(missing, ", "))
+		}
+	}
+
+	envLayer, err = envLayer.Reset()
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	// The lifecycle prepends the bin directory of every launch layer to the
+	// PATH of the running application. The layer is cached so that it can be
+	// reused on a rebuild.
+	envLayer.Launch, envLayer.Cache = true, true
+
+	args := []string{"env", "update", "--prefix", envLayer.Path, "--file", envFile, "--prune"}
+
+	// `conda env update` cannot be restricted to a channel, so the packages
+	// of the environment file are installed from the vendored channel with
+	// `conda create` instead.
+	if found {
+		args = append([]string{
+			"create", "--yes",
+			"--prefix", envLayer.Path,
+			"--offline",
+			"--override-channels",
+			"--channel", "file://" + vendored.Path,
+		}, specs...)
+	}
+
+	logger.Process("Installing conda environment from %s", filepath.Base(envFile))
+	if found {
+		logger.Subprocess("Using vendored channel %s", vendored.Path)
+	}
+	logger.Subprocess("Running 'conda %s'", strings.Join(args, " "))
+
+	duration, err := clock.Measure(func() error {
+		return condaRunner.Execute(condaLayer.Path, pexec.Execution{
+			Args: args,
+			Dir:  context.WorkingDir,
+		})
+	})
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	logger.Action("Completed in %s", duration.Round(time.Millisecond))
+	logger.Break()
+
+	envLayer.Metadata = map[string]interface{}{
+		EnvironmentKey:      envChecksum,
+		CondaFingerprintKey: condaFingerprint,
+	}
+
+	if vendorChecksum != "" {
+		envLayer.Metadata[VendorKey] = vendorChecksum
+	}
+
+	setActivatedEnvironment(&envLayer)
+
+	return envLayer, nil
+}
+
+// installLockFile installs the packages that the lock file pins for the given
+// platform into the conda-env layer with `conda create`. The layer is cached
+// and reused as long as neither the lock file nor the conda installation
+// change.
+func installLockFile(
+	context packit.BuildContext,
+	condaRunner CondaRunner,
+	condaLayer packit.Layer,
+	lockFile string,
+	platform string,
+	condaFingerprint string,
+	logger scribe.Emitter,
+	clock chronos.Clock,
+) (packit.Layer, error) {
+	lockChecksum, err := fs.NewChecksumCalculator().Sum(lockFile)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	envLayer, err := context.Layers.Get("conda-env")
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	cachedLockChecksum, _ := envLayer.Metadata[LockKey].(string)
+	cachedPlatform, _ := envLayer.Metadata[PlatformKey].(string)
+	cachedCondaFingerprint, _ := envLayer.Metadata[CondaFingerprintKey].(string)
+	if cachedLockChecksum == lockChecksum && cachedPlatform == platform && cachedCondaFingerprint == condaFingerprint {
+		logger.Process("Reusing cached layer %s", envLayer.Path)
+		logger.Break()
+
+		envLayer.Launch, envLayer.Cache = true, true
+		setActivatedEnvironment(&envLayer)
+
+		return envLayer, nil
+	}
+
+	spec, err := RenderExplicitSpec(lockFile, platform)
+	if err != nil {
+		return packit.Layer{}, fmt.Errorf("failed to install conda environment: %w", err)
+	}
+
+	// Like the installer script layer, this layer has no type set to true, so
+	// the lifecycle will ensure that it is removed.
+	specLayer, err := context.Layers.Get("conda-lock-temp-layer")
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	specLayer, err = specLayer.Reset()
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	specFile := filepath.Join(specLayer.Path, fmt.Sprintf("%s.txt", platform))
+	err = os.WriteFile(specFile, spec, 0644)
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	envLayer, err = envLayer.Reset()
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	envLayer.Launch, envLayer.Cache = true, true
+
+	args := []string{"create", "--yes", "--prefix", envLayer.Path, "--file", specFile}
+
+	logger.Process("Installing conda environment from %s for %s", filepath.Base(lockFile), platform)
+	logger.Subprocess("Running 'conda %s'", strings.Join(args, " "))
+
+	duration, err := clock.Measure(func() error {
+		return condaRunner.Execute(condaLayer.Path, pexec.Execution{
+			Args: args,
+			Dir:  context.WorkingDir,
+		})
+	})
+	if err != nil {
+		return packit.Layer{}, err
+	}
+
+	logger.Action("Completed in %s", duration.Round(time.Millisecond))
+	logger.Break()
+
+	envLayer.Metadata = map[string]interface{}{
+		LockKey:             lockChecksum,
+		PlatformKey:         platform,
+		CondaFingerprintKey: condaFingerprint,
+	}
+	setActivatedEnvironment(&envLayer)
+
+	return envLayer, nil
+}
@@ -0,0 +1,34 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+)
+
+type CondaRunner struct {
+	ExecuteCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			CondaLayerPath string
+			Execution      pexec.Execution
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(string, pexec.Execution) error
+	}
+}
+
+func (f *CondaRunner) Execute(param1 string, param2 pexec.Execution) error {
+	f.ExecuteCall.mutex.Lock()
+	defer f.ExecuteCall.mutex.Unlock()
+	f.ExecuteCall.CallCount++
+	f.ExecuteCall.Receives.CondaLayerPath = param1
+	f.ExecuteCall.Receives.Execution = param2
+	if f.ExecuteCall.Stub != nil {
+		return f.ExecuteCall.Stub(param1, param2)
+	}
+	return f.ExecuteCall.Returns.Error
+}
@@ -0,0 +1,39 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+type Installer struct {
+	InstallCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Dependency   postal.Dependency
+			ArtifactPath string
+			LayerPath    string
+			Options      miniconda.InstallOptions
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(postal.Dependency, string, string, miniconda.InstallOptions) error
+	}
+}
+
+func (f *Installer) Install(param1 postal.Dependency, param2 string, param3 string, param4 miniconda.InstallOptions) error {
+	f.InstallCall.mutex.Lock()
+	defer f.InstallCall.mutex.Unlock()
+	f.InstallCall.CallCount++
+	f.InstallCall.Receives.Dependency = param1
+	f.InstallCall.Receives.ArtifactPath = param2
+	f.InstallCall.Receives.LayerPath = param3
+	f.InstallCall.Receives.Options = param4
+	if f.InstallCall.Stub != nil {
+		return f.InstallCall.Stub(param1, param2, param3, param4)
+	}
+	return f.InstallCall.Returns.Error
+}
@@ -9,8 +9,20 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("miniconda", spec.Report(report.Terminal{}), spec.Parallel())
-	suite("Build", testBuild)
-	suite("Detect", testDetect)
-	suite("ScriptRunner", testScriptRunner)
+	suite("BasePackages", testBasePackages)
+	suite("Build", testBuild, spec.Sequential())
+	suite("Clean", testClean)
+	suite("Config", testConfig, spec.Sequential())
+	suite("CondaExecutor", testCondaExecutor)
+	suite("CondaSBOMGenerator", testCondaSBOMGenerator)
+	suite("Detect", testDetect, spec.Sequential())
+	suite("Distribution", testDistribution, spec.Sequential())
+	suite("Installer", testInstaller)
+	suite("LayerMetadata", testLayerMetadata)
+	suite("LockFile", testLockFile)
+	suite("PrefixInstaller", testPrefixInstaller)
+	suite("ShellInstaller", testShellInstaller)
+	suite("Solver", testSolver)
+	suite("TarballInstaller", testTarballInstaller)
 	suite.Run(t)
 }
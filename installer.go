@@ -0,0 +1,153 @@
+package miniconda
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// InstallOptions are the options that are passed to an Installer. Installers
+// that do not run a process, such as the TarballInstaller and the
+// PrefixInstaller, support none of them and fail when any is set.
+type InstallOptions struct {
+	// Args are passed to the installer in addition to its default arguments.
+	Args []string
+
+	// Env is added to the environment of the installer.
+	Env []string
+
+	// Timeout limits how long the installer may run. A zero Timeout does not
+	// limit the installer.
+	Timeout time.Duration
+
+	// WorkingDir is the directory from which the installer is invoked.
+	WorkingDir string
+}
+
+// requireNone returns an error naming the options that are set, for the
+// installers that support none of them.
+func (o InstallOptions) requireNone(dependency postal.Dependency, kind InstallerKind) error {
+	var options []string
+	if len(o.Args) > 0 {
+		options = append(options, "Args")
+	}
+
+	if len(o.Env) > 0 {
+		options = append(options, "Env")
+	}
+
+	if o.Timeout > 0 {
+		options = append(options, "Timeout")
+	}
+
+	if o.WorkingDir != "" {
+		options = append(options, "WorkingDir")
+	}
+
+	if len(options) > 0 {
+		return fmt.Errorf("failed to install %s: the %s installer does not support the install options %s", dependency.ID, kind, strings.Join(options, ", "))
+	}
+
+	return nil
+}
+
+// InstallerKind is the type of artifact that a dependency provides, which
+// determines how it is installed.
+type InstallerKind string
+
+const (
+	// ShellInstallerKind is an installer script, such as the Miniconda and
+	// Miniforge installers.
+	ShellInstallerKind InstallerKind = "shell"
+
+	// TarballInstallerKind is an archive with executables in its bin
+	// directory.
+	TarballInstallerKind InstallerKind = "tarball"
+
+	// PrefixInstallerKind is an archive of a complete conda prefix, such as
+	// one created by conda-pack.
+	PrefixInstallerKind InstallerKind = "prefix"
+)
+
+// archiveExtensions are the extensions of the archives that postal extracts
+// when delivering a dependency.
+var archiveExtensions = []string{".tar.bz2", ".tar.gz", ".tar.xz", ".tgz", ".tar", ".zip"}
+
+// InstallerKindFor returns the kind of installer that the given dependency
+// declares through its name: shell installers are named `<name>.sh`, archives
+// of executables `<name>-bin` and archives of a pre-built prefix
+// `<name>-prefix`, followed by the archive extension. Any other name, or a URI
+// that is not of the same type of artifact as the name, is ambiguous and
+// results in an error.
+func InstallerKindFor(dependency postal.Dependency) (InstallerKind, error) {
+	name := strings.ToLower(dependency.Name)
+
+	var kind InstallerKind
+	if strings.HasSuffix(name, ".sh") {
+		kind = ShellInstallerKind
+	} else if extension, ok := archiveExtension(name); ok {
+		switch {
+		case strings.HasSuffix(strings.TrimSuffix(name, extension), "-bin"):
+			kind = TarballInstallerKind
+		case strings.HasSuffix(strings.TrimSuffix(name, extension), "-prefix"):
+			kind = PrefixInstallerKind
+		}
+	}
+
+	if kind == "" {
+		return "", fmt.Errorf("failed to determine installer of dependency %s: name %q must end in .sh, or in -bin or -prefix followed by an archive extension", dependency.ID, dependency.Name)
+	}
+
+	if dependency.URI != "" {
+		uri := strings.ToLower(path.Base(dependency.URI))
+		_, archive := archiveExtension(uri)
+		if (kind == ShellInstallerKind && !strings.HasSuffix(uri, ".sh")) || (kind != ShellInstallerKind && !archive) {
+			return "", fmt.Errorf("failed to determine installer of dependency %s: name %q declares a %s installer, but uri %q is not of that type", dependency.ID, dependency.Name, kind, dependency.URI)
+		}
+	}
+
+	return kind, nil
+}
+
+func archiveExtension(name string) (string, bool) {
+	for _, extension := range archiveExtensions {
+		if strings.HasSuffix(name, extension) {
+			return extension, true
+		}
+	}
+
+	return "", false
+}
+
+// DependencyInstaller implements the Installer interface by delegating to the
+// installer of the kind that the dependency requires.
+type DependencyInstaller struct {
+	installers map[InstallerKind]Installer
+}
+
+// NewDependencyInstaller creates an instance of the DependencyInstaller given
+// the installers of each kind.
+func NewDependencyInstaller(installers map[InstallerKind]Installer) DependencyInstaller {
+	return DependencyInstaller{
+		installers: installers,
+	}
+}
+
+// Install installs the dependency delivered to artifactPath into the layer at
+// layerPath using the installer of the kind that the dependency requires.
+func (d DependencyInstaller) Install(dependency postal.Dependency, artifactPath, layerPath string, options InstallOptions) error {
+	kind, err := InstallerKindFor(dependency)
+	if err != nil {
+		return err
+	}
+
+	installer, ok := d.installers[kind]
+	if !ok {
+		return fmt.Errorf("failed to install dependency %s: no %s installer available", dependency.ID, kind)
+	}
+
+	return installer.Install(dependency, artifactPath, layerPath, options)
+}
@@ -0,0 +1,130 @@
+package miniconda_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/miniconda/fakes"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testInstaller(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("InstallerKindFor", func() {
+		it("selects the installer that the name of the dependency declares", func() {
+			for name, expected := range map[string]miniconda.InstallerKind{
+				"Miniconda.sh":        miniconda.ShellInstallerKind,
+				"conda-bin.tar.bz2":   miniconda.TarballInstallerKind,
+				"tools-bin.TGZ":       miniconda.TarballInstallerKind,
+				"conda-prefix.tar.gz": miniconda.PrefixInstallerKind,
+				"app-prefix.zip":      miniconda.PrefixInstallerKind,
+			} {
+				kind, err := miniconda.InstallerKindFor(postal.Dependency{Name: name})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(kind).To(Equal(expected), name)
+			}
+		})
+
+		it("accepts a URI of the same type of artifact", func() {
+			kind, err := miniconda.InstallerKindFor(postal.Dependency{
+				Name: "conda-bin.tar.bz2",
+				URI:  "https://example.com/conda-24.3.0-0.tar.bz2",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kind).To(Equal(miniconda.TarballInstallerKind))
+		})
+
+		context("failure cases", func() {
+			context("when the name does not declare an installer", func() {
+				it("returns an error", func() {
+					for _, name := range []string{"some-binary", "conda.tar.bz2", "conda-pack.tar.gz", "conda-prefix"} {
+						_, err := miniconda.InstallerKindFor(postal.Dependency{
+							ID:   "some-dependency",
+							Name: name,
+							URI:  "https://example.com/conda-24.3.0-0.tar.bz2",
+						})
+						Expect(err).To(MatchError(fmt.Sprintf(`failed to determine installer of dependency some-dependency: name %q must end in .sh, or in -bin or -prefix followed by an archive extension`, name)))
+					}
+				})
+			})
+
+			context("when the URI is not of the type that the name declares", func() {
+				it("returns an error", func() {
+					_, err := miniconda.InstallerKindFor(postal.Dependency{
+						ID:   "some-dependency",
+						Name: "Miniconda.sh",
+						URI:  "https://example.com/conda-prefix.tar.gz",
+					})
+					Expect(err).To(MatchError(`failed to determine installer of dependency some-dependency: name "Miniconda.sh" declares a shell installer, but uri "https://example.com/conda-prefix.tar.gz" is not of that type`))
+
+					_, err = miniconda.InstallerKindFor(postal.Dependency{
+						ID:   "some-dependency",
+						Name: "conda-prefix.tar.gz",
+						URI:  "https://example.com/Miniconda3-latest-Linux-x86_64.sh",
+					})
+					Expect(err).To(MatchError(`failed to determine installer of dependency some-dependency: name "conda-prefix.tar.gz" declares a prefix installer, but uri "https://example.com/Miniconda3-latest-Linux-x86_64.sh" is not of that type`))
+				})
+			})
+		})
+	})
+
+	context("DependencyInstaller", func() {
+		var (
+			shellInstaller   *fakes.Installer
+			tarballInstaller *fakes.Installer
+
+			dependencyInstaller miniconda.DependencyInstaller
+		)
+
+		it.Before(func() {
+			shellInstaller = &fakes.Installer{}
+			tarballInstaller = &fakes.Installer{}
+
+			dependencyInstaller = miniconda.NewDependencyInstaller(map[miniconda.InstallerKind]miniconda.Installer{
+				miniconda.ShellInstallerKind:   shellInstaller,
+				miniconda.TarballInstallerKind: tarballInstaller,
+			})
+		})
+
+		it("delegates to the installer of the dependency", func() {
+			dependency := postal.Dependency{ID: "conda", Name: "conda-bin.tar.bz2"}
+			options := miniconda.InstallOptions{WorkingDir: "some-dir"}
+
+			err := dependencyInstaller.Install(dependency, "artifact-path", "layer-path", options)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(shellInstaller.InstallCall.CallCount).To(Equal(0))
+			Expect(tarballInstaller.InstallCall.CallCount).To(Equal(1))
+			Expect(tarballInstaller.InstallCall.Receives.Dependency).To(Equal(dependency))
+			Expect(tarballInstaller.InstallCall.Receives.ArtifactPath).To(Equal("artifact-path"))
+			Expect(tarballInstaller.InstallCall.Receives.LayerPath).To(Equal("layer-path"))
+			Expect(tarballInstaller.InstallCall.Receives.Options).To(Equal(options))
+		})
+
+		context("failure cases", func() {
+			context("when there is no installer of the required kind", func() {
+				it("returns an error", func() {
+					err := dependencyInstaller.Install(postal.Dependency{ID: "conda-prefix", Name: "conda-prefix.tar.gz"}, "artifact-path", "layer-path", miniconda.InstallOptions{})
+					Expect(err).To(MatchError("failed to install dependency conda-prefix: no prefix installer available"))
+				})
+			})
+
+			context("when the installer fails", func() {
+				it.Before(func() {
+					shellInstaller.InstallCall.Returns.Error = errors.New("installer failed")
+				})
+
+				it("returns the error", func() {
+					err := dependencyInstaller.Install(postal.Dependency{ID: "miniconda3", Name: "Miniconda.sh"}, "artifact-path", "layer-path", miniconda.InstallOptions{})
+					Expect(err).To(MatchError("installer failed"))
+				})
+			})
+		})
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/sclevine/spec"
 
 	. "github.com/onsi/gomega"
+	. "github.com/paketo-buildpacks/occam/matchers"
 )
 
 func testOffline(t *testing.T, context spec.G, it spec.S) {
@@ -55,6 +56,7 @@ func testOffline(t *testing.T, context spec.G, it spec.S) {
 
 			var logs fmt.Stringer
 			image, logs, err = pack.WithNoColor().Build.
+				WithEnv(map[string]string{"BP_MINICONDA_SOLVER": "libmamba"}).
 				WithPullPolicy("never").
 				WithBuildpacks(
 					settings.Buildpacks.Miniconda.Offline,
@@ -64,6 +66,9 @@ func testOffline(t *testing.T, context spec.G, it spec.S) {
 				Execute(name, source)
 
 			Expect(err).NotTo(HaveOccurred(), logs.String())
+			Expect(logs).To(ContainLines(
+				MatchRegexp(`    Using the mamba solver bundled with Miniconda \d+\.\d+\.\d+`),
+			))
 
 			container, err = docker.Container.Run.
 				WithCommand("conda info").
@@ -0,0 +1,60 @@
+package miniconda
+
+import (
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+// setCondaEnvironment sets the environment variables that describe the conda
+// installation in the given layer. The variables are available during build
+// and/or launch, depending on the types of the layer. A layer that is neither
+// available during build nor during launch has no environment.
+func setCondaEnvironment(layer *packit.Layer, withCondarc bool) {
+	env := scopedEnvironment(layer)
+	if env == nil {
+		return
+	}
+
+	env.Override("CONDA_EXE", filepath.Join(layer.Path, "bin", "conda"))
+	env.Override("CONDA_PREFIX", layer.Path)
+	env.Override("CONDA_DEFAULT_ENV", "base")
+	env.Override("CONDA_PKGS_DIRS", filepath.Join(layer.Path, "pkgs"))
+
+	if withCondarc {
+		env.Override("CONDARC", filepath.Join(layer.Path, ".condarc"))
+	}
+}
+
+// setActivatedEnvironment sets the environment variables that activate the
+// conda environment installed in the given layer. As the layer comes after the
+// conda layer, these override the values set by setCondaEnvironment.
+func setActivatedEnvironment(layer *packit.Layer) {
+	env := scopedEnvironment(layer)
+	if env == nil {
+		return
+	}
+
+	env.Override("CONDA_PREFIX", layer.Path)
+	env.Override("CONDA_DEFAULT_ENV", layer.Path)
+}
+
+// scopedEnvironment clears any environment that the layer had from a previous
+// build, as the types of the layer may have changed, and returns the
+// environment matching its current types.
+func scopedEnvironment(layer *packit.Layer) packit.Environment {
+	layer.SharedEnv = packit.Environment{}
+	layer.BuildEnv = packit.Environment{}
+	layer.LaunchEnv = packit.Environment{}
+
+	switch {
+	case layer.Build && layer.Launch:
+		return layer.SharedEnv
+	case layer.Build:
+		return layer.BuildEnv
+	case layer.Launch:
+		return layer.LaunchEnv
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package miniconda
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// CondaLayerMetadata is the record of every input that the conda layer is
+// built from. It is stored in the layer metadata alongside a fingerprint of
+// all inputs, and compared field by field with the inputs of the current
+// build to determine whether the layer can be reused.
+type CondaLayerMetadata struct {
+	DependencyChecksum    string   `json:"dependency-sha"`
+	Distribution          string   `json:"distribution"`
+	CondarcChecksum       string   `json:"condarc-sha"`
+	Solver                string   `json:"solver"`
+	SolverChannelChecksum string   `json:"solver-channel-sha"`
+	BasePackages          []string `json:"base-packages"`
+	Cleanup               string   `json:"clean"`
+	BuildpackVersion      string   `json:"buildpack-version"`
+}
+
+// ParseCondaLayerMetadata reads the record from the metadata of a conda layer.
+// Inputs that were not recorded by earlier versions of the buildpack are set
+// to the value that those versions used.
+func ParseCondaLayerMetadata(metadata map[string]interface{}) CondaLayerMetadata {
+	record := CondaLayerMetadata{
+		Distribution: string(MinicondaDistribution),
+		Solver:       string(ClassicSolver),
+		Cleanup:      string(CleanNone),
+	}
+
+	record.DependencyChecksum, _ = metadata[DepKey].(string)
+	record.CondarcChecksum, _ = metadata[CondarcKey].(string)
+	record.SolverChannelChecksum, _ = metadata[SolverChannelKey].(string)
+	record.BuildpackVersion, _ = metadata[BuildpackVersionKey].(string)
+
+	if distribution, ok := metadata[DistributionKey].(string); ok {
+		record.Distribution = distribution
+	}
+
+	if solver, ok := metadata[SolverKey].(string); ok {
+		record.Solver = solver
+	}
+
+	if cleanup, ok := metadata[CleanKey].(string); ok {
+		record.Cleanup = cleanup
+	}
+
+	record.BasePackages = stringList(metadata[BasePackagesKey])
+
+	return record
+}
+
+// stringList converts a list of the layer metadata into a list of strings, as
+// lists are read back from the layer TOML file as []interface{}.
+func stringList(value interface{}) []string {
+	switch values := value.(type) {
+	case []string:
+		return values
+	case []interface{}:
+		var list []string
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	default:
+		return nil
+	}
+}
+
+// dependencyChecksum returns the checksum of the dependency, falling back to
+// the deprecated SHA256 field when Checksum is not present.
+func dependencyChecksum(dependency postal.Dependency) string {
+	if dependency.Checksum != "" {
+		return dependency.Checksum
+	}
+
+	//nolint:staticcheck // SHA256 is only a fallback in case Checksum is not present
+	return dependency.SHA256
+}
+
+// Fingerprint returns a checksum of all inputs of the record.
+func (m CondaLayerMetadata) Fingerprint() string {
+	// Marshalling a struct cannot fail and its fields are always encoded in
+	// the same order.
+	content, _ := json.Marshal(m)
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Changes returns a description of every input that differs between the
+// previous record and this one, or nothing when the layer built from the
+// previous record can be reused.
+func (m CondaLayerMetadata) Changes(previous CondaLayerMetadata) []string {
+	var changes []string
+	if previous.DependencyChecksum == "" || !cargo.Checksum(previous.DependencyChecksum).MatchString(m.DependencyChecksum) {
+		changes = append(changes, fmt.Sprintf("dependency changed from %q to %q", previous.DependencyChecksum, m.DependencyChecksum))
+	}
+
+	if previous.Distribution != m.Distribution {
+		changes = append(changes, fmt.Sprintf("distribution changed from %q to %q", previous.Distribution, m.Distribution))
+	}
+
+	if previous.Solver != m.Solver {
+		changes = append(changes, fmt.Sprintf("solver changed from %q to %q", previous.Solver, m.Solver))
+	}
+
+	if previous.SolverChannelChecksum != m.SolverChannelChecksum {
+		changes = append(changes, fmt.Sprintf("solver channel changed from %q to %q", previous.SolverChannelChecksum, m.SolverChannelChecksum))
+	}
+
+	if strings.Join(previous.BasePackages, " ") != strings.Join(m.BasePackages, " ") {
+		changes = append(changes, fmt.Sprintf("base packages changed from %q to %q", strings.Join(previous.BasePackages, " "), strings.Join(m.BasePackages, " ")))
+	}
+
+	if previous.Cleanup != m.Cleanup {
+		changes = append(changes, fmt.Sprintf("cleanup changed from %q to %q", previous.Cleanup, m.Cleanup))
+	}
+
+	if previous.CondarcChecksum != m.CondarcChecksum {
+		changes = append(changes, "conda configuration changed")
+	}
+
+	if previous.BuildpackVersion != m.BuildpackVersion {
+		version := previous.BuildpackVersion
+		if version == "" {
+			version = "<unknown>"
+		}
+
+		changes = append(changes, fmt.Sprintf("buildpack version changed from %q to %q", version, m.BuildpackVersion))
+	}
+
+	return changes
+}
+
+// Metadata returns the layer metadata that records the inputs and their
+// fingerprint. Inputs that are empty are left out.
+func (m CondaLayerMetadata) Metadata() map[string]interface{} {
+	metadata := map[string]interface{}{
+		DepKey:          m.DependencyChecksum,
+		DistributionKey: m.Distribution,
+		SolverKey:       m.Solver,
+		FingerprintKey:  m.Fingerprint(),
+	}
+
+	if len(m.BasePackages) > 0 {
+		metadata[BasePackagesKey] = m.BasePackages
+	}
+
+	if m.CondarcChecksum != "" {
+		metadata[CondarcKey] = m.CondarcChecksum
+	}
+
+	if m.SolverChannelChecksum != "" {
+		metadata[SolverChannelKey] = m.SolverChannelChecksum
+	}
+
+	if m.Cleanup != string(CleanNone) {
+		metadata[CleanKey] = m.Cleanup
+	}
+
+	if m.BuildpackVersion != "" {
+		metadata[BuildpackVersionKey] = m.BuildpackVersion
+	}
+
+	return metadata
+}
@@ -0,0 +1,125 @@
+package miniconda_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testLayerMetadata(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		record miniconda.CondaLayerMetadata
+	)
+
+	it.Before(func() {
+		record = miniconda.CondaLayerMetadata{
+			DependencyChecksum:    "sha256:some-dependency-sha",
+			Distribution:          "miniforge",
+			CondarcChecksum:       "some-condarc-sha",
+			Solver:                "libmamba",
+			SolverChannelChecksum: "sha256:some-channel-sha",
+			BasePackages:          []string{"conda-build", "pip>=23"},
+			Cleanup:               "packages",
+			BuildpackVersion:      "some-version",
+		}
+	})
+
+	context("ParseCondaLayerMetadata", func() {
+		it("reads the record from the layer metadata", func() {
+			Expect(miniconda.ParseCondaLayerMetadata(map[string]interface{}{
+				"dependency-sha":     "sha256:some-dependency-sha",
+				"distribution":       "miniforge",
+				"condarc-sha":        "some-condarc-sha",
+				"solver":             "libmamba",
+				"solver-channel-sha": "sha256:some-channel-sha",
+				"base-packages":      []interface{}{"conda-build", "pip>=23"},
+				"clean":              "packages",
+				"buildpack-version":  "some-version",
+				"fingerprint":        "some-fingerprint",
+			})).To(Equal(record))
+		})
+
+		it("defaults the inputs that earlier versions did not record", func() {
+			Expect(miniconda.ParseCondaLayerMetadata(map[string]interface{}{
+				"dependency-sha": "some-dependency-sha",
+			})).To(Equal(miniconda.CondaLayerMetadata{
+				DependencyChecksum: "some-dependency-sha",
+				Distribution:       "miniconda",
+				Solver:             "classic",
+				Cleanup:            "none",
+			}))
+		})
+	})
+
+	context("Fingerprint", func() {
+		it("changes whenever an input changes", func() {
+			fingerprint := record.Fingerprint()
+			Expect(fingerprint).To(MatchRegexp(`^[0-9a-f]{64}$`))
+			Expect(record.Fingerprint()).To(Equal(fingerprint))
+
+			changed := record
+			changed.SolverChannelChecksum = "sha256:other-channel-sha"
+			Expect(changed.Fingerprint()).NotTo(Equal(fingerprint))
+
+			changed = record
+			changed.BasePackages = []string{"conda-build"}
+			Expect(changed.Fingerprint()).NotTo(Equal(fingerprint))
+
+			changed = record
+			changed.BuildpackVersion = "other-version"
+			Expect(changed.Fingerprint()).NotTo(Equal(fingerprint))
+		})
+	})
+
+	context("Changes", func() {
+		it("returns nothing when no input changed", func() {
+			previous := record
+			previous.DependencyChecksum = "some-dependency-sha"
+
+			Expect(record.Changes(previous)).To(BeEmpty())
+		})
+
+		it("describes every input that changed", func() {
+			Expect(record.Changes(miniconda.CondaLayerMetadata{
+				DependencyChecksum: "sha256:other-dependency-sha",
+				Distribution:       "miniconda",
+				Solver:             "classic",
+				Cleanup:            "none",
+			})).To(Equal([]string{
+				`dependency changed from "sha256:other-dependency-sha" to "sha256:some-dependency-sha"`,
+				`distribution changed from "miniconda" to "miniforge"`,
+				`solver changed from "classic" to "libmamba"`,
+				`solver channel changed from "" to "sha256:some-channel-sha"`,
+				`base packages changed from "" to "conda-build pip>=23"`,
+				`cleanup changed from "none" to "packages"`,
+				"conda configuration changed",
+				`buildpack version changed from "<unknown>" to "some-version"`,
+			}))
+		})
+	})
+
+	context("Metadata", func() {
+		it("returns the layer metadata of the record", func() {
+			Expect(record.Metadata()).To(Equal(map[string]interface{}{
+				"dependency-sha":     "sha256:some-dependency-sha",
+				"distribution":       "miniforge",
+				"condarc-sha":        "some-condarc-sha",
+				"solver":             "libmamba",
+				"solver-channel-sha": "sha256:some-channel-sha",
+				"base-packages":      []string{"conda-build", "pip>=23"},
+				"clean":              "packages",
+				"buildpack-version":  "some-version",
+				"fingerprint":        record.Fingerprint(),
+			}))
+		})
+
+		it("can be read back", func() {
+			Expect(miniconda.ParseCondaLayerMetadata(record.Metadata())).To(Equal(record))
+		})
+	})
+}
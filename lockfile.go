@@ -0,0 +1,255 @@
+package miniconda
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+	"gopkg.in/yaml.v3"
+)
+
+// ExplicitMarker is the line that marks a conda explicit spec file, as
+// written by `conda list --explicit`.
+const ExplicitMarker = "@EXPLICIT"
+
+// CondaLockFile is the name of the lock file written by conda-lock.
+const CondaLockFile = "conda-lock.yml"
+
+// PackageListFile is the name of the platform-neutral explicit spec file.
+const PackageListFile = "package-list.txt"
+
+// ExplicitSpecFiles returns the files that are installed as explicit spec
+// files for the given conda platform when they contain the ExplicitMarker, in
+// order of preference: the lock file of the platform, as written by
+// `conda-lock --kind explicit`, and then PackageListFile. The lock files of
+// other platforms are ignored.
+func ExplicitSpecFiles(platform string) []string {
+	return []string{
+		fmt.Sprintf("conda-%s.lock", platform),
+		PackageListFile,
+	}
+}
+
+// CondaPlatform returns the conda platform, or subdir, of the given Go
+// architecture.
+func CondaPlatform(arch string) (string, error) {
+	switch arch {
+	case "amd64":
+		return "linux-64", nil
+	case "arm64":
+		return "linux-aarch64", nil
+	default:
+		return "", fmt.Errorf("unsupported architecture %q: must be one of amd64 or arm64", arch)
+	}
+}
+
+// FindLockFile returns the path of the lock file for the given conda platform
+// in the working directory, preferring conda-lock.yml over explicit spec
+// files, or an empty path when there is none.
+func FindLockFile(workingDir, platform string) (string, error) {
+	lockPath := filepath.Join(workingDir, CondaLockFile)
+	exists, err := fs.Exists(lockPath)
+	if err != nil {
+		return "", err
+	}
+
+	if exists {
+		return lockPath, nil
+	}
+
+	for _, file := range ExplicitSpecFiles(platform) {
+		content, err := os.ReadFile(filepath.Join(workingDir, file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", err
+		}
+
+		if isExplicitSpec(content) {
+			return filepath.Join(workingDir, file), nil
+		}
+	}
+
+	return "", nil
+}
+
+func isExplicitSpec(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == ExplicitMarker {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RenderExplicitSpec returns an explicit spec file with the packages of the
+// lock file at lockPath for the given platform.
+func RenderExplicitSpec(lockPath, platform string) ([]byte, error) {
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	if filepath.Base(lockPath) == CondaLockFile {
+		urls, err = CondaLockURLs(content, platform)
+	} else {
+		urls, err = ExplicitURLs(content, platform)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file %s: %w", filepath.Base(lockPath), err)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	fmt.Fprintf(buffer, "# platform: %s\n%s\n", platform, ExplicitMarker)
+	for _, u := range urls {
+		fmt.Fprintln(buffer, u)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// ExplicitURLs returns the package URLs of an explicit spec file that are
+// built for the given platform or are noarch. A file that declares a
+// different platform, or that has no packages for the platform, does not
+// cover it.
+func ExplicitURLs(content []byte, platform string) ([]string, error) {
+	var (
+		urls     []string
+		declared string
+		covered  bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if value, ok := strings.CutPrefix(line, "# platform:"); ok {
+			declared = strings.TrimSpace(value)
+			continue
+		}
+
+		if line == "" || line == ExplicitMarker || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		subdir, err := packageSubdir(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch subdir {
+		case platform:
+			covered = true
+			urls = append(urls, line)
+		case "noarch":
+			urls = append(urls, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if declared != "" && declared != platform {
+		return nil, fmt.Errorf("the lock file is for platform %s and does not cover platform %s", declared, platform)
+	}
+
+	if declared == "" && !covered {
+		return nil, fmt.Errorf("the lock file does not cover platform %s", platform)
+	}
+
+	return urls, nil
+}
+
+// packageSubdir returns the subdir of the channel that the package URL points
+// into, which is the directory containing the package file.
+func packageSubdir(line string) (string, error) {
+	location, _, _ := strings.Cut(line, "#")
+
+	u, err := url.Parse(location)
+	if err != nil || u.Path == "" {
+		return "", fmt.Errorf("invalid package URL %q", line)
+	}
+
+	return path.Base(path.Dir(u.Path)), nil
+}
+
+type condaLock struct {
+	Metadata struct {
+		Platforms []string `yaml:"platforms"`
+	} `yaml:"metadata"`
+	Package []struct {
+		Name     string            `yaml:"name"`
+		Manager  string            `yaml:"manager"`
+		Platform string            `yaml:"platform"`
+		URL      string            `yaml:"url"`
+		Hash     map[string]string `yaml:"hash"`
+	} `yaml:"package"`
+}
+
+// CondaLockURLs returns the URLs of the conda packages that a conda-lock.yml
+// file locks for the given platform. Packages that are managed by pip cannot
+// be part of an explicit spec file, so a lock file with pip packages for the
+// platform cannot be installed.
+func CondaLockURLs(content []byte, platform string) ([]string, error) {
+	var lock condaLock
+	err := yaml.Unmarshal(content, &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	var covered bool
+	for _, p := range lock.Metadata.Platforms {
+		if p == platform {
+			covered = true
+		}
+	}
+
+	if !covered {
+		return nil, fmt.Errorf("the lock file covers platforms %s and does not cover platform %s", strings.Join(lock.Metadata.Platforms, ", "), platform)
+	}
+
+	var urls, pip []string
+	for _, p := range lock.Package {
+		if p.Platform != platform {
+			continue
+		}
+
+		if p.Manager == "pip" {
+			pip = append(pip, p.Name)
+			continue
+		}
+
+		if p.Manager != "conda" {
+			continue
+		}
+
+		if p.URL == "" {
+			return nil, fmt.Errorf("package %s has no url", p.Name)
+		}
+
+		// Explicit spec files pin packages by their md5 hash.
+		if md5 := p.Hash["md5"]; md5 != "" {
+			urls = append(urls, fmt.Sprintf("%s#%s", p.URL, md5))
+			continue
+		}
+
+		urls = append(urls, p.URL)
+	}
+
+	if len(pip) > 0 {
+		return nil, fmt.Errorf("the lock file has pip packages for platform %s, which cannot be installed from an explicit spec file: %s", platform, strings.Join(pip, ", "))
+	}
+
+	return urls, nil
+}
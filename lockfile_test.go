@@ -0,0 +1,327 @@
+package miniconda_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testLockFile(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("CondaPlatform", func() {
+		it("returns the conda platform of the architecture", func() {
+			platform, err := miniconda.CondaPlatform("amd64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(platform).To(Equal("linux-64"))
+
+			platform, err = miniconda.CondaPlatform("arm64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(platform).To(Equal("linux-aarch64"))
+		})
+
+		context("failure cases", func() {
+			context("when the architecture is not supported", func() {
+				it("returns an error", func() {
+					_, err := miniconda.CondaPlatform("s390x")
+					Expect(err).To(MatchError(`unsupported architecture "s390x": must be one of amd64 or arm64`))
+				})
+			})
+		})
+	})
+
+	context("FindLockFile", func() {
+		var workingDir string
+
+		it.Before(func() {
+			var err error
+			workingDir, err = os.MkdirTemp("", "working-dir")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workingDir)).To(Succeed())
+		})
+
+		it("prefers conda-lock.yml", func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "conda-lock.yml"), []byte("version: 1\n"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "conda-linux-64.lock"), []byte("@EXPLICIT\n"), 0600)).To(Succeed())
+
+			lockFile, err := miniconda.FindLockFile(workingDir, "linux-64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lockFile).To(Equal(filepath.Join(workingDir, "conda-lock.yml")))
+		})
+
+		it("selects the lock file of the platform over package-list.txt", func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "package-list.txt"), []byte("@EXPLICIT\n"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "conda-linux-64.lock"), []byte("@EXPLICIT\n"), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "conda-linux-aarch64.lock"), []byte("@EXPLICIT\n"), 0600)).To(Succeed())
+
+			lockFile, err := miniconda.FindLockFile(workingDir, "linux-64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lockFile).To(Equal(filepath.Join(workingDir, "conda-linux-64.lock")))
+
+			lockFile, err = miniconda.FindLockFile(workingDir, "linux-aarch64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lockFile).To(Equal(filepath.Join(workingDir, "conda-linux-aarch64.lock")))
+		})
+
+		it("ignores the lock files of other platforms", func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "conda-linux-aarch64.lock"), []byte("@EXPLICIT\n"), 0600)).To(Succeed())
+
+			lockFile, err := miniconda.FindLockFile(workingDir, "linux-64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lockFile).To(BeEmpty())
+
+			Expect(os.WriteFile(filepath.Join(workingDir, "package-list.txt"), []byte("@EXPLICIT\n"), 0600)).To(Succeed())
+
+			lockFile, err = miniconda.FindLockFile(workingDir, "linux-64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lockFile).To(Equal(filepath.Join(workingDir, "package-list.txt")))
+		})
+
+		it("ignores files that are not explicit spec files", func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "package-list.txt"), []byte("python=3.12.1=hab00c5b_1_cpython\n"), 0600)).To(Succeed())
+
+			lockFile, err := miniconda.FindLockFile(workingDir, "linux-64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lockFile).To(BeEmpty())
+		})
+	})
+
+	context("ExplicitURLs", func() {
+		it("returns the packages of the platform and noarch packages", func() {
+			urls, err := miniconda.ExplicitURLs([]byte(`# platform: linux-64
+@EXPLICIT
+https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda#5b1b7ba4e5fa8e9fcd4ec92fd7d5ee58
+https://conda.anaconda.org/conda-forge/noarch/pip-24.0-pyhd8ed1ab_0.conda
+`), "linux-64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(urls).To(Equal([]string{
+				"https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda#5b1b7ba4e5fa8e9fcd4ec92fd7d5ee58",
+				"https://conda.anaconda.org/conda-forge/noarch/pip-24.0-pyhd8ed1ab_0.conda",
+			}))
+		})
+
+		it("filters a file without platform declaration for the platform", func() {
+			urls, err := miniconda.ExplicitURLs([]byte(`@EXPLICIT
+https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda
+https://conda.anaconda.org/conda-forge/linux-aarch64/python-3.12.1-h43d1f9e_1_cpython.conda
+`), "linux-aarch64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(urls).To(Equal([]string{
+				"https://conda.anaconda.org/conda-forge/linux-aarch64/python-3.12.1-h43d1f9e_1_cpython.conda",
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the file is for another platform", func() {
+				it("returns an error", func() {
+					_, err := miniconda.ExplicitURLs([]byte(`# platform: linux-aarch64
+@EXPLICIT
+https://conda.anaconda.org/conda-forge/noarch/pip-24.0-pyhd8ed1ab_0.conda
+`), "linux-64")
+					Expect(err).To(MatchError("the lock file is for platform linux-aarch64 and does not cover platform linux-64"))
+				})
+			})
+
+			context("when the file has no packages of the platform", func() {
+				it("returns an error", func() {
+					_, err := miniconda.ExplicitURLs([]byte(`@EXPLICIT
+https://conda.anaconda.org/conda-forge/linux-aarch64/python-3.12.1-h43d1f9e_1_cpython.conda
+`), "linux-64")
+					Expect(err).To(MatchError("the lock file does not cover platform linux-64"))
+				})
+			})
+
+			context("when a package URL is invalid", func() {
+				it("returns an error", func() {
+					_, err := miniconda.ExplicitURLs([]byte("@EXPLICIT\n%zz\n"), "linux-64")
+					Expect(err).To(MatchError(`invalid package URL "%zz"`))
+				})
+			})
+		})
+	})
+
+	context("CondaLockURLs", func() {
+		var content []byte
+
+		it.Before(func() {
+			content = []byte(`version: 1
+metadata:
+  platforms:
+    - linux-64
+    - linux-aarch64
+package:
+  - name: python
+    manager: conda
+    platform: linux-64
+    url: https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda
+    hash:
+      md5: 5b1b7ba4e5fa8e9fcd4ec92fd7d5ee58
+  - name: python
+    manager: conda
+    platform: linux-aarch64
+    url: https://conda.anaconda.org/conda-forge/linux-aarch64/python-3.12.1-h43d1f9e_1_cpython.conda
+    hash:
+      md5: 7d4e6f0e9cd8d3e4a8bce0a6f1c2b3a4
+  - name: pip
+    manager: conda
+    platform: linux-aarch64
+    url: https://conda.anaconda.org/conda-forge/noarch/pip-24.0-pyhd8ed1ab_0.conda
+  - name: requests
+    manager: pip
+    platform: osx-arm64
+    url: https://files.pythonhosted.org/packages/requests-2.31.0-py3-none-any.whl
+`)
+		})
+
+		it("returns the conda packages locked for the platform", func() {
+			urls, err := miniconda.CondaLockURLs(content, "linux-64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(urls).To(Equal([]string{
+				"https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda#5b1b7ba4e5fa8e9fcd4ec92fd7d5ee58",
+			}))
+
+			urls, err = miniconda.CondaLockURLs(content, "linux-aarch64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(urls).To(Equal([]string{
+				"https://conda.anaconda.org/conda-forge/linux-aarch64/python-3.12.1-h43d1f9e_1_cpython.conda#7d4e6f0e9cd8d3e4a8bce0a6f1c2b3a4",
+				"https://conda.anaconda.org/conda-forge/noarch/pip-24.0-pyhd8ed1ab_0.conda",
+			}))
+		})
+
+		context("failure cases", func() {
+			context("when the lock file does not cover the platform", func() {
+				it("returns an error", func() {
+					_, err := miniconda.CondaLockURLs(content, "osx-arm64")
+					Expect(err).To(MatchError("the lock file covers platforms linux-64, linux-aarch64 and does not cover platform osx-arm64"))
+				})
+			})
+
+			context("when the lock file has pip packages for the platform", func() {
+				it("returns an error", func() {
+					_, err := miniconda.CondaLockURLs([]byte(`metadata:
+  platforms: [linux-64]
+package:
+  - name: python
+    manager: conda
+    platform: linux-64
+    url: https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda
+  - name: requests
+    manager: pip
+    platform: linux-64
+    url: https://files.pythonhosted.org/packages/requests-2.31.0-py3-none-any.whl
+  - name: urllib3
+    manager: pip
+    platform: linux-64
+    url: https://files.pythonhosted.org/packages/urllib3-2.2.1-py3-none-any.whl
+`), "linux-64")
+					Expect(err).To(MatchError("the lock file has pip packages for platform linux-64, which cannot be installed from an explicit spec file: requests, urllib3"))
+				})
+			})
+
+			context("when a package has no url", func() {
+				it("returns an error", func() {
+					_, err := miniconda.CondaLockURLs([]byte(`metadata:
+  platforms: [linux-64]
+package:
+  - name: python
+    manager: conda
+    platform: linux-64
+`), "linux-64")
+					Expect(err).To(MatchError("package python has no url"))
+				})
+			})
+
+			context("when the lock file is not valid YAML", func() {
+				it("returns an error", func() {
+					_, err := miniconda.CondaLockURLs([]byte("%%%"), "linux-64")
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	context("RenderExplicitSpec", func() {
+		var workingDir string
+
+		it.Before(func() {
+			var err error
+			workingDir, err = os.MkdirTemp("", "working-dir")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(workingDir)).To(Succeed())
+		})
+
+		it("renders an explicit spec file from a multi-platform conda-lock.yml", func() {
+			lockPath := filepath.Join(workingDir, "conda-lock.yml")
+			Expect(os.WriteFile(lockPath, []byte(`metadata:
+  platforms: [linux-64, linux-aarch64]
+package:
+  - name: python
+    manager: conda
+    platform: linux-64
+    url: https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda
+    hash:
+      md5: 5b1b7ba4e5fa8e9fcd4ec92fd7d5ee58
+  - name: python
+    manager: conda
+    platform: linux-aarch64
+    url: https://conda.anaconda.org/conda-forge/linux-aarch64/python-3.12.1-h43d1f9e_1_cpython.conda
+`), 0600)).To(Succeed())
+
+			content, err := miniconda.RenderExplicitSpec(lockPath, "linux-aarch64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(`# platform: linux-aarch64
+@EXPLICIT
+https://conda.anaconda.org/conda-forge/linux-aarch64/python-3.12.1-h43d1f9e_1_cpython.conda
+`))
+		})
+
+		it("renders an explicit spec file from an explicit spec file", func() {
+			lockPath := filepath.Join(workingDir, "conda-linux-64.lock")
+			Expect(os.WriteFile(lockPath, []byte(`# Generated by conda-lock.
+# platform: linux-64
+@EXPLICIT
+https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda#5b1b7ba4e5fa8e9fcd4ec92fd7d5ee58
+`), 0600)).To(Succeed())
+
+			content, err := miniconda.RenderExplicitSpec(lockPath, "linux-64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(`# platform: linux-64
+@EXPLICIT
+https://conda.anaconda.org/conda-forge/linux-64/python-3.12.1-hab00c5b_1_cpython.conda#5b1b7ba4e5fa8e9fcd4ec92fd7d5ee58
+`))
+		})
+
+		context("failure cases", func() {
+			context("when the spec file is for the wrong platform", func() {
+				it("returns an error", func() {
+					lockPath := filepath.Join(workingDir, "package-list.txt")
+					Expect(os.WriteFile(lockPath, []byte(`# platform: linux-aarch64
+@EXPLICIT
+https://conda.anaconda.org/conda-forge/linux-aarch64/python-3.12.1-h43d1f9e_1_cpython.conda
+`), 0600)).To(Succeed())
+
+					_, err := miniconda.RenderExplicitSpec(lockPath, "linux-64")
+					Expect(err).To(MatchError("failed to read lock file package-list.txt: the lock file is for platform linux-aarch64 and does not cover platform linux-64"))
+				})
+			})
+
+			context("when the lock file cannot be read", func() {
+				it("returns an error", func() {
+					_, err := miniconda.RenderExplicitSpec(filepath.Join(workingDir, "conda-lock.yml"), "linux-64")
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package miniconda
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// DefaultPackageCacheLimit is the size in megabytes above which the package
+// cache is pruned when $BP_CONDA_PKGS_CACHE_LIMIT is not set.
+const DefaultPackageCacheLimit = 2048
+
+// PackageCache is the cache-only conda-pkgs layer that conda downloads and
+// extracts packages into during the build. It is kept across builds,
+// independently of the conda layer.
+type PackageCache struct {
+	Layer packit.Layer
+
+	// Limit is the size in bytes above which the cache is pruned.
+	Limit int64
+}
+
+// preparePackageCache returns the conda-pkgs layer, unless it is disabled
+// through $BP_CONDA_PKGS_CACHE.
+func preparePackageCache(context packit.BuildContext, logger scribe.Emitter) (PackageCache, bool, error) {
+	enabled, err := strconv.ParseBool(GetEnvOrDefault("BP_CONDA_PKGS_CACHE", "true"))
+	if err != nil {
+		return PackageCache{}, false, fmt.Errorf("failed to parse BP_CONDA_PKGS_CACHE: %w", err)
+	}
+
+	if !enabled {
+		return PackageCache{}, false, nil
+	}
+
+	limit, err := strconv.ParseInt(GetEnvOrDefault("BP_CONDA_PKGS_CACHE_LIMIT", strconv.Itoa(DefaultPackageCacheLimit)), 10, 64)
+	if err != nil || limit <= 0 {
+		return PackageCache{}, false, fmt.Errorf("failed to parse BP_CONDA_PKGS_CACHE_LIMIT: must be a positive number of megabytes")
+	}
+
+	layer, err := context.Layers.Get("conda-pkgs")
+	if err != nil {
+		return PackageCache{}, false, err
+	}
+
+	layer.Cache = true
+
+	size, err := directorySize(layer.Path)
+	if err != nil {
+		return PackageCache{}, false, err
+	}
+
+	logger.Process("Using package cache %s (%s)", layer.Path, formatBytes(size))
+	logger.Break()
+
+	return PackageCache{Layer: layer, Limit: limit * 1024 * 1024}, true, nil
+}
+
+// Prune removes the least recently modified packages from the cache until
+// its size is below the limit.
+func (p PackageCache) Prune(logger scribe.Emitter) error {
+	size, err := directorySize(p.Layer.Path)
+	if err != nil {
+		return err
+	}
+
+	if size <= p.Limit {
+		return nil
+	}
+
+	entries, err := os.ReadDir(p.Layer.Path)
+	if err != nil {
+		return err
+	}
+
+	type pkg struct {
+		path string
+		info fs.FileInfo
+	}
+
+	var pkgs []pkg
+	for _, entry := range entries {
+		// The index cache and the list of downloaded URLs are small and are
+		// rewritten by conda on every run.
+		if entry.Name() == "cache" || entry.Name() == "urls" || entry.Name() == "urls.txt" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		pkgs = append(pkgs, pkg{path: filepath.Join(p.Layer.Path, entry.Name()), info: info})
+	}
+
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return pkgs[i].info.ModTime().Before(pkgs[j].info.ModTime())
+	})
+
+	var removed int
+	initial := size
+	for _, pkg := range pkgs {
+		if size <= p.Limit {
+			break
+		}
+
+		pkgSize, err := directorySize(pkg.path)
+		if err != nil {
+			return err
+		}
+
+		err = os.RemoveAll(pkg.path)
+		if err != nil {
+			return err
+		}
+
+		size -= pkgSize
+		removed++
+	}
+
+	logger.Process("Pruning package cache")
+	logger.Subprocess("Removed %d entries (%s) to stay below %s", removed, formatBytes(initial-size), formatBytes(p.Limit))
+	logger.Break()
+
+	return nil
+}
+
+func directorySize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,53 @@
+package miniconda
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// PrefixInstaller implements the Installer interface for archives of a
+// complete conda prefix. As conda records the absolute path of the prefix in
+// the installed files, the prefix must have been built at the path of the
+// layer it is installed into.
+type PrefixInstaller struct{}
+
+// NewPrefixInstaller creates an instance of the PrefixInstaller.
+func NewPrefixInstaller() PrefixInstaller {
+	return PrefixInstaller{}
+}
+
+// Install copies the prefix that postal extracted into artifactPath into the
+// layer at condaLayerPath. No install options are supported.
+func (p PrefixInstaller) Install(dependency postal.Dependency, artifactPath, condaLayerPath string, options InstallOptions) error {
+	err := options.requireNone(dependency, PrefixInstallerKind)
+	if err != nil {
+		return err
+	}
+
+	exists, err := fs.Exists(filepath.Join(artifactPath, "conda-meta"))
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("failed to install %s: %s is not a conda prefix as it does not contain conda-meta", dependency.ID, artifactPath)
+	}
+
+	entries, err := os.ReadDir(artifactPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = fs.Copy(filepath.Join(artifactPath, entry.Name()), filepath.Join(condaLayerPath, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to install %s: %w", dependency.ID, err)
+		}
+	}
+
+	return nil
+}
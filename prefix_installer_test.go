@@ -0,0 +1,86 @@
+package miniconda_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testPrefixInstaller(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath  string
+		prefixDir  string
+		dependency postal.Dependency
+
+		prefixInstaller miniconda.PrefixInstaller
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "conda-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		prefixDir, err = os.MkdirTemp("", "conda-prefix")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(prefixDir, "bin"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(prefixDir, "bin", "conda"), []byte("conda"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(prefixDir, "conda-meta"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(prefixDir, "conda-meta", "history"), nil, 0644)).To(Succeed())
+
+		dependency = postal.Dependency{
+			ID:   "conda-prefix",
+			Name: "conda-prefix.tar.gz",
+		}
+
+		prefixInstaller = miniconda.NewPrefixInstaller()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+		Expect(os.RemoveAll(prefixDir)).To(Succeed())
+	})
+
+	context("Install", func() {
+		it("copies the prefix into the layer", func() {
+			err := prefixInstaller.Install(dependency, prefixDir, layerPath, miniconda.InstallOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(layerPath, "bin", "conda")).To(BeARegularFile())
+			Expect(filepath.Join(layerPath, "conda-meta", "history")).To(BeARegularFile())
+		})
+
+		context("failure cases", func() {
+			context("when the archive is not a conda prefix", func() {
+				it.Before(func() {
+					Expect(os.RemoveAll(filepath.Join(prefixDir, "conda-meta"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := prefixInstaller.Install(dependency, prefixDir, layerPath, miniconda.InstallOptions{})
+					Expect(err).To(MatchError(ContainSubstring("failed to install conda-prefix: %s is not a conda prefix", prefixDir)))
+				})
+			})
+
+			context("when install options are given", func() {
+				it("returns an error", func() {
+					err := prefixInstaller.Install(dependency, prefixDir, layerPath, miniconda.InstallOptions{
+						Args:    []string{"-s"},
+						Timeout: time.Minute,
+					})
+					Expect(err).To(MatchError("failed to install conda-prefix: the prefix installer does not support the install options Args, Timeout"))
+					Expect(filepath.Join(layerPath, "conda-meta")).NotTo(BeADirectory())
+				})
+			})
+		})
+	})
+}
@@ -7,18 +7,11 @@ import (
 	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/paketo-buildpacks/packit/v2/cargo"
 	"github.com/paketo-buildpacks/packit/v2/chronos"
-	"github.com/paketo-buildpacks/packit/v2/pexec"
 	"github.com/paketo-buildpacks/packit/v2/postal"
-	"github.com/paketo-buildpacks/packit/v2/sbom"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/paketo-buildpacks/packit/v2/servicebindings"
 )
 
-type Generator struct{}
-
-func (f Generator) GenerateFromDependency(dependency postal.Dependency, path string) (sbom.SBOM, error) {
-	return sbom.GenerateFromDependency(dependency, path)
-}
-
 func main() {
 	logger := scribe.NewEmitter(os.Stdout).WithLevel(os.Getenv("BP_LOG_LEVEL"))
 
@@ -26,8 +19,14 @@ func main() {
 		miniconda.Detect(),
 		miniconda.Build(
 			postal.NewService(cargo.NewTransport()),
-			miniconda.NewScriptRunner(pexec.NewExecutable("bash")),
-			Generator{},
+			miniconda.NewDependencyInstaller(map[miniconda.InstallerKind]miniconda.Installer{
+				miniconda.ShellInstallerKind:   miniconda.NewShellInstaller(miniconda.NewCommandExecutable("bash"), logger),
+				miniconda.TarballInstallerKind: miniconda.NewTarballInstaller(),
+				miniconda.PrefixInstallerKind:  miniconda.NewPrefixInstaller(),
+			}),
+			miniconda.NewCondaExecutor(logger),
+			servicebindings.NewResolver(),
+			miniconda.NewCondaSBOMGenerator(),
 			logger,
 			chronos.DefaultClock,
 		),
@@ -0,0 +1,176 @@
+package miniconda
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/anchore/packageurl-go"
+	"github.com/anchore/syft/syft/cpe"
+	"github.com/anchore/syft/syft/file"
+	"github.com/anchore/syft/syft/pkg"
+	syftsbom "github.com/anchore/syft/syft/sbom"
+	"github.com/anchore/syft/syft/source"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/sbom"
+)
+
+// CondaSBOMGenerator implements the SBOMGenerator interface. Next to the
+// dependency itself, the SBOM lists every conda package that is recorded in
+// the conda-meta directory of the installation.
+type CondaSBOMGenerator struct{}
+
+// NewCondaSBOMGenerator creates an instance of the CondaSBOMGenerator.
+func NewCondaSBOMGenerator() CondaSBOMGenerator {
+	return CondaSBOMGenerator{}
+}
+
+// GenerateFromDependency returns an SBOM for the given dependency and the
+// conda packages installed into dir.
+func (g CondaSBOMGenerator) GenerateFromDependency(dependency postal.Dependency, dir string) (sbom.SBOM, error) {
+	dependencyPackage, err := packageFromDependency(dependency)
+	if err != nil {
+		return sbom.SBOM{}, err
+	}
+
+	packages, err := CondaPackages(dir)
+	if err != nil {
+		return sbom.SBOM{}, err
+	}
+
+	catalog := pkg.NewCollection(dependencyPackage)
+	for _, meta := range packages {
+		catalog.Add(packageFromCondaMeta(dir, meta))
+	}
+
+	return sbom.NewSBOM(syftsbom.SBOM{
+		Artifacts: syftsbom.Artifacts{
+			Packages: catalog,
+		},
+		Source: source.Description{
+			Metadata: source.DirectoryMetadata{
+				Path: dir,
+			},
+		},
+	}), nil
+}
+
+// CondaPackages returns the packages recorded in the conda-meta directory of
+// the conda prefix at dir, sorted by name.
+func CondaPackages(dir string) ([]pkg.CondaMetaPackage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "conda-meta", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []pkg.CondaMetaPackage
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		var meta pkg.CondaMetaPackage
+		err = json.Unmarshal(content, &meta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse conda package record %s: %w", path, err)
+		}
+
+		if meta.Name == "" {
+			continue
+		}
+
+		packages = append(packages, meta)
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+
+	return packages, nil
+}
+
+func packageFromCondaMeta(dir string, meta pkg.CondaMetaPackage) pkg.Package {
+	// The list of files of a package is not relevant to the SBOM and can be
+	// very large.
+	meta.Files = nil
+	meta.PathsData = nil
+	meta.Link = nil
+	meta.ExtractedPackageDir = ""
+
+	qualifiers := packageurl.Qualifiers{}
+	for _, qualifier := range []packageurl.Qualifier{
+		{Key: "build", Value: meta.Build},
+		{Key: "channel", Value: meta.Channel},
+		{Key: "subdir", Value: meta.Subdir},
+	} {
+		if qualifier.Value != "" {
+			qualifiers = append(qualifiers, qualifier)
+		}
+	}
+
+	licenses := pkg.NewLicenseSet()
+	if meta.License != "" {
+		licenses.Add(pkg.NewLicense(meta.License))
+	}
+
+	location := file.NewLocation(filepath.Join(dir, "conda-meta", fmt.Sprintf("%s-%s-%s.json", meta.Name, meta.Version, meta.Build)))
+
+	p := pkg.Package{
+		Name:      meta.Name,
+		Version:   meta.Version,
+		Type:      pkg.CondaPkg,
+		Licenses:  licenses,
+		Locations: file.NewLocationSet(location),
+		PURL:      packageurl.NewPackageURL("conda", "", meta.Name, meta.Version, qualifiers, "").ToString(),
+		Metadata:  meta,
+	}
+	p.SetID()
+
+	return p
+}
+
+// packageFromDependency mirrors sbom.GenerateFromDependency, which only
+// returns an opaque SBOM that cannot be extended with more packages.
+func packageFromDependency(dependency postal.Dependency) (pkg.Package, error) {
+	cpes := dependency.CPEs
+	if len(cpes) == 0 {
+		//nolint:staticcheck // CPE is only a fallback in case CPEs is not present
+		cpes = []string{dependency.CPE}
+	}
+
+	var parsed []cpe.CPE
+	for _, value := range cpes {
+		if value == "" {
+			value = sbom.UnknownCPE
+		}
+
+		c, err := cpe.New(value, cpe.DeclaredSource)
+		if err != nil {
+			return pkg.Package{}, err
+		}
+		parsed = append(parsed, c)
+	}
+
+	licenses := pkg.NewLicenseSet()
+	for _, license := range dependency.Licenses {
+		licenses.Add(pkg.NewLicense(license))
+	}
+
+	p := pkg.Package{
+		Name:     dependency.Name,
+		Version:  dependency.Version,
+		Licenses: licenses,
+		CPEs:     parsed,
+		PURL:     dependency.PURL,
+	}
+	p.SetID()
+
+	return p, nil
+}
@@ -0,0 +1,139 @@
+package miniconda_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/sbom"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCondaSBOMGenerator(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath  string
+		dependency postal.Dependency
+
+		generator miniconda.CondaSBOMGenerator
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "conda-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(layerPath, "conda-meta"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layerPath, "conda-meta", "python-3.12.1-h996f2a0_0.json"), []byte(`{
+  "name": "python",
+  "version": "3.12.1",
+  "build": "h996f2a0_0",
+  "channel": "https://repo.anaconda.com/pkgs/main/linux-64",
+  "subdir": "linux-64",
+  "license": "PSF-2.0",
+  "files": ["bin/python3.12"]
+}`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layerPath, "conda-meta", "openssl-3.0.12-h7f8727e_0.json"), []byte(`{
+  "name": "openssl",
+  "version": "3.0.12",
+  "build": "h7f8727e_0",
+  "channel": "https://repo.anaconda.com/pkgs/main/linux-64",
+  "subdir": "linux-64",
+  "license": "Apache-2.0"
+}`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(layerPath, "conda-meta", "history"), []byte("==> 2024-01-01 <==\n"), 0644)).To(Succeed())
+
+		dependency = postal.Dependency{
+			ID:       "miniconda3",
+			Name:     "miniconda3",
+			Version:  "24.1.2",
+			CPEs:     []string{"cpe:2.3:a:anaconda:miniconda3:24.1.2:*:*:*:*:python:*:*"},
+			PURL:     "pkg:generic/miniconda3@24.1.2",
+			Licenses: []string{"BSD-3-Clause"},
+		}
+
+		generator = miniconda.NewCondaSBOMGenerator()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+	})
+
+	context("GenerateFromDependency", func() {
+		it("lists the dependency and the installed conda packages", func() {
+			bom, err := generator.GenerateFromDependency(dependency, layerPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			formatter, err := bom.InFormats(sbom.CycloneDXFormat, sbom.SPDXFormat, sbom.SyftFormat)
+			Expect(err).NotTo(HaveOccurred())
+
+			formats := formatter.Formats()
+			Expect(formats).To(HaveLen(3))
+
+			for _, format := range formats {
+				content, err := io.ReadAll(format.Content)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(string(content)).To(ContainSubstring("pkg:generic/miniconda3@24.1.2"), format.Extension)
+				Expect(string(content)).To(ContainSubstring("pkg:conda/python@3.12.1?build=h996f2a0_0"), format.Extension)
+				Expect(string(content)).To(ContainSubstring("pkg:conda/openssl@3.0.12?build=h7f8727e_0"), format.Extension)
+				Expect(string(content)).To(ContainSubstring("subdir=linux-64"), format.Extension)
+				Expect(string(content)).To(ContainSubstring("PSF-2.0"), format.Extension)
+				Expect(string(content)).To(ContainSubstring("Apache-2.0"), format.Extension)
+				Expect(string(content)).NotTo(ContainSubstring("bin/python3.12"), format.Extension)
+			}
+		})
+
+		context("when there is no conda-meta directory", func() {
+			it.Before(func() {
+				Expect(os.RemoveAll(filepath.Join(layerPath, "conda-meta"))).To(Succeed())
+			})
+
+			it("only lists the dependency", func() {
+				bom, err := generator.GenerateFromDependency(dependency, layerPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				formatter, err := bom.InFormats(sbom.SyftFormat)
+				Expect(err).NotTo(HaveOccurred())
+
+				content := bytes.NewBuffer(nil)
+				_, err = io.Copy(content, formatter.Formats()[0].Content)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(content.String()).To(ContainSubstring("pkg:generic/miniconda3@24.1.2"))
+				Expect(content.String()).NotTo(ContainSubstring("pkg:conda/"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when a package record cannot be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(layerPath, "conda-meta", "broken-1.0-0.json"), []byte("%%%"), 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := generator.GenerateFromDependency(dependency, layerPath)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse conda package record")))
+				})
+			})
+
+			context("when the CPE is malformed", func() {
+				it.Before(func() {
+					dependency.CPEs = []string{"not-a-cpe"}
+				})
+
+				it("returns an error", func() {
+					_, err := generator.GenerateFromDependency(dependency, layerPath)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,241 @@
+package miniconda
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/paketo-buildpacks/packit/v2/cargo"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+//go:generate faux --interface Executable --output fakes/executable.go
+
+// OutputTailLines is the number of lines of installer output that are
+// included in the error when the installer fails.
+const OutputTailLines = 20
+
+// InstallerHeaderMarker is the line of the installer scripts generated by
+// conda constructor, such as the Miniconda and Miniforge installers, that
+// separates the shell script from the payload.
+const InstallerHeaderMarker = "@@END_HEADER@@"
+
+// installerHeaderLimit is the number of bytes at the start of an installer in
+// which the InstallerHeaderMarker must be found.
+const installerHeaderLimit = 1 << 20
+
+var errInstallerTimeout = errors.New("timed out")
+
+// killWaitDelay is how long CommandExecutable waits for the output of a killed
+// process to be closed, as processes started by it may keep it open.
+const killWaitDelay = 5 * time.Second
+
+// Executable defines the interface for invoking an executable, which is
+// killed when the given context is done.
+type Executable interface {
+	Execute(ctx context.Context, execution pexec.Execution) error
+}
+
+// CommandExecutable implements the Executable interface for an executable on
+// the $PATH or at a given path.
+type CommandExecutable struct {
+	name string
+}
+
+// NewCommandExecutable creates an instance of the CommandExecutable given the
+// name or path of the executable.
+func NewCommandExecutable(name string) CommandExecutable {
+	return CommandExecutable{
+		name: name,
+	}
+}
+
+// Execute invokes the executable with the given execution and kills it when
+// the context is done. Like pexec, an execution without an environment
+// inherits the environment of the current process.
+func (e CommandExecutable) Execute(ctx context.Context, execution pexec.Execution) error {
+	cmd := exec.CommandContext(ctx, e.name, execution.Args...)
+	cmd.Dir = execution.Dir
+	cmd.Stdout = execution.Stdout
+	cmd.Stderr = execution.Stderr
+	cmd.Stdin = execution.Stdin
+	cmd.WaitDelay = killWaitDelay
+
+	if len(execution.Env) > 0 {
+		cmd.Env = execution.Env
+	}
+
+	return cmd.Run()
+}
+
+// ShellInstaller implements the Installer interface for installer scripts,
+// such as the Miniconda and Miniforge installers.
+type ShellInstaller struct {
+	executable Executable
+	logger     scribe.Emitter
+}
+
+// NewShellInstaller creates an instance of the ShellInstaller given an
+// Executable that runs `bash`. The output of the script is written to the
+// debug log of the given logger.
+func NewShellInstaller(executable Executable, logger scribe.Emitter) ShellInstaller {
+	return ShellInstaller{
+		executable: executable,
+		logger:     logger,
+	}
+}
+
+// Install invokes the installer script of the dependency delivered to
+// artifactPath, which installs conda into the layer at condaLayerPath. The
+// script is only run once it has been validated to be the installer of the
+// dependency. When the script fails, the last lines of its output are
+// included in the returned error.
+func (s ShellInstaller) Install(dependency postal.Dependency, artifactPath, condaLayerPath string, options InstallOptions) error {
+	scriptPath := filepath.Join(artifactPath, dependency.Name)
+	err := validateInstaller(dependency, scriptPath)
+	if err != nil {
+		return err
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	output := io.MultiWriter(buffer, s.logger.Debug.ActionWriter)
+
+	execution := pexec.Execution{
+		Args: append([]string{
+			scriptPath,
+			"-b",
+			"-f",
+			"-p", condaLayerPath,
+		}, options.Args...),
+		Dir:    options.WorkingDir,
+		Stdout: output,
+		Stderr: output,
+	}
+
+	// An execution with an environment does not inherit the environment of
+	// the current process.
+	if len(options.Env) > 0 {
+		execution.Env = append(os.Environ(), options.Env...)
+	}
+
+	err = s.execute(execution, options.Timeout)
+	if err != nil {
+		// The output of a killed script is of no help.
+		if errors.Is(err, errInstallerTimeout) {
+			return fmt.Errorf("failed while running %s install script: %w", dependency.ID, err)
+		}
+
+		if lines := tailLines(buffer.String(), OutputTailLines); len(lines) > 0 {
+			return fmt.Errorf("failed while running %s install script: %w\nlast %d lines of output:\n%s", dependency.ID, err, len(lines), strings.Join(lines, "\n"))
+		}
+
+		return fmt.Errorf("failed while running %s install script: %w", dependency.ID, err)
+	}
+
+	return nil
+}
+
+// validateInstaller checks that the file at path exists, matches the checksum
+// of the dependency and is a shell installer generated by conda constructor,
+// so that a misconfigured dependency is never run by bash.
+func validateInstaller(dependency postal.Dependency, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to validate installer of %s: %s does not exist", dependency.ID, path)
+		}
+		return fmt.Errorf("failed to validate installer of %s: %w", dependency.ID, err)
+	}
+	defer file.Close()
+
+	checksum := cargo.Checksum(dependency.Checksum)
+	if dependency.Checksum == "" {
+		//nolint:staticcheck // SHA256 is only a fallback in case Checksum is not present
+		checksum = cargo.Checksum(dependency.SHA256)
+	}
+
+	if checksum.Hash() == "" {
+		return fmt.Errorf("failed to validate installer of %s: the dependency has no checksum", dependency.ID)
+	}
+
+	if !strings.EqualFold(checksum.Algorithm(), "sha256") {
+		return fmt.Errorf("failed to validate installer of %s: unsupported checksum algorithm %q", dependency.ID, checksum.Algorithm())
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return fmt.Errorf("failed to validate installer of %s: %w", dependency.ID, err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, checksum.Hash()) {
+		return fmt.Errorf("failed to validate installer of %s: checksum of %s is sha256:%s, expected sha256:%s", dependency.ID, path, actual, checksum.Hash())
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("failed to validate installer of %s: %w", dependency.ID, err)
+	}
+
+	header := make([]byte, installerHeaderLimit)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to validate installer of %s: %w", dependency.ID, err)
+	}
+	header = header[:n]
+
+	if !bytes.HasPrefix(header, []byte("#!")) {
+		return fmt.Errorf("failed to validate installer of %s: %s is not a shell script", dependency.ID, path)
+	}
+
+	if !bytes.Contains(header, []byte(InstallerHeaderMarker)) {
+		return fmt.Errorf("failed to validate installer of %s: %s is not a conda installer as it has no %s marker", dependency.ID, path, InstallerHeaderMarker)
+	}
+
+	return nil
+}
+
+// execute runs the execution and kills the script once the timeout has
+// passed.
+func (s ShellInstaller) execute(execution pexec.Execution, timeout time.Duration) error {
+	if timeout <= 0 {
+		return s.executable.Execute(context.Background(), execution)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := s.executable.Execute(ctx, execution)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", errInstallerTimeout, timeout)
+	}
+
+	return err
+}
+
+// tailLines returns at most the last n lines of the given output, or nothing
+// when there is no output.
+func tailLines(output string, n int) []string {
+	output = strings.TrimRight(output, "\n")
+	if strings.TrimSpace(output) == "" {
+		return nil
+	}
+
+	lines := strings.Split(output, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return lines
+}
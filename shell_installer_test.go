@@ -0,0 +1,289 @@
+package miniconda_test
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/miniconda/fakes"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testShellInstaller(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layersDir  string
+		scriptDir  string
+		dependency postal.Dependency
+
+		executable *fakes.Executable
+		buffer     *bytes.Buffer
+
+		shellInstaller miniconda.ShellInstaller
+	)
+
+	it.Before(func() {
+		var err error
+		layersDir, err = os.MkdirTemp("", "layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		scriptDir, err = os.MkdirTemp("", "miniconda-script-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		script := "#!/bin/sh\nexit 0\n@@END_HEADER@@\npayload"
+		err = os.WriteFile(filepath.Join(scriptDir, "artifact.sh"), []byte(script), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		dependency = postal.Dependency{
+			ID:       "miniconda3",
+			Name:     "artifact.sh",
+			Checksum: "sha256:" + sha256Hex(script),
+		}
+
+		executable = &fakes.Executable{}
+		executable.ExecuteCall.Stub = func(ctx gocontext.Context, execution pexec.Execution) error {
+			for i := 1; i <= 25; i++ {
+				fmt.Fprintf(execution.Stdout, "installing package %d\n", i)
+			}
+			fmt.Fprintln(execution.Stderr, "some warning")
+
+			return nil
+		}
+
+		buffer = bytes.NewBuffer(nil)
+		shellInstaller = miniconda.NewShellInstaller(executable, scribe.NewEmitter(buffer).WithLevel("DEBUG"))
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layersDir)).To(Succeed())
+		Expect(os.RemoveAll(scriptDir)).To(Succeed())
+	})
+
+	context("Install", func() {
+		it("runs the install script with the correct flags", func() {
+			err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(executable.ExecuteCall.Receives.Execution.Args).To(Equal([]string{
+				filepath.Join(scriptDir, "artifact.sh"),
+				"-b",
+				"-f",
+				"-p", layersDir,
+			}))
+			Expect(executable.ExecuteCall.Receives.Execution.Env).To(BeNil())
+			Expect(executable.ExecuteCall.Receives.Execution.Dir).To(BeEmpty())
+		})
+
+		context("when options are given", func() {
+			it("passes them to the install script", func() {
+				err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{
+					Args:       []string{"-s"},
+					Env:        []string{"SOME_VAR=some-value"},
+					WorkingDir: scriptDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executable.ExecuteCall.Receives.Execution.Args).To(Equal([]string{
+					filepath.Join(scriptDir, "artifact.sh"),
+					"-b",
+					"-f",
+					"-p", layersDir,
+					"-s",
+				}))
+				Expect(executable.ExecuteCall.Receives.Execution.Env).To(ContainElement("SOME_VAR=some-value"))
+				Expect(executable.ExecuteCall.Receives.Execution.Env).To(ContainElements(os.Environ()))
+				Expect(executable.ExecuteCall.Receives.Execution.Dir).To(Equal(scriptDir))
+			})
+		})
+
+		it("streams the output of the script to the debug log", func() {
+			err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.String()).To(ContainSubstring("installing package 1\n"))
+			Expect(buffer.String()).To(ContainSubstring("installing package 25\n"))
+			Expect(buffer.String()).To(ContainSubstring("some warning\n"))
+		})
+
+		context("when the log level is not debug", func() {
+			it.Before(func() {
+				shellInstaller = miniconda.NewShellInstaller(executable, scribe.NewEmitter(buffer))
+			})
+
+			it("does not log the output of the script", func() {
+				err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.String()).To(BeEmpty())
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the script fails", func() {
+				it.Before(func() {
+					stub := executable.ExecuteCall.Stub
+					executable.ExecuteCall.Stub = func(ctx gocontext.Context, execution pexec.Execution) error {
+						Expect(stub(ctx, execution)).To(Succeed())
+						return errors.New("script failed to run")
+					}
+				})
+
+				it("returns an error that includes the last lines of output", func() {
+					var lines []string
+					for i := 7; i <= 25; i++ {
+						lines = append(lines, fmt.Sprintf("installing package %d", i))
+					}
+					lines = append(lines, "some warning")
+
+					err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+					Expect(err).To(MatchError(fmt.Sprintf("failed while running miniconda3 install script: script failed to run\nlast 20 lines of output:\n%s", strings.Join(lines, "\n"))))
+				})
+
+				context("when the script has no output", func() {
+					it.Before(func() {
+						executable.ExecuteCall.Stub = nil
+						executable.ExecuteCall.Returns.Error = errors.New("script failed to run")
+					})
+
+					it("returns an error", func() {
+						err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+						Expect(err).To(MatchError("failed while running miniconda3 install script: script failed to run"))
+					})
+				})
+			})
+
+			context("when the installer does not exist", func() {
+				it.Before(func() {
+					Expect(os.Remove(filepath.Join(scriptDir, "artifact.sh"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+					Expect(err).To(MatchError(fmt.Sprintf("failed to validate installer of miniconda3: %s does not exist", filepath.Join(scriptDir, "artifact.sh"))))
+					Expect(executable.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			context("when the checksum of the installer does not match", func() {
+				it.Before(func() {
+					dependency.Checksum = "sha256:" + sha256Hex("something else")
+				})
+
+				it("returns an error", func() {
+					err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+					Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("failed to validate installer of miniconda3: checksum of %s is sha256:", filepath.Join(scriptDir, "artifact.sh")))))
+					Expect(err).To(MatchError(ContainSubstring("expected sha256:" + sha256Hex("something else"))))
+					Expect(executable.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			context("when the dependency has no checksum", func() {
+				it.Before(func() {
+					dependency.Checksum = ""
+				})
+
+				it("returns an error", func() {
+					err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+					Expect(err).To(MatchError("failed to validate installer of miniconda3: the dependency has no checksum"))
+				})
+			})
+
+			context("when the checksum is not a sha256", func() {
+				it.Before(func() {
+					dependency.Checksum = "sha512:some-hash"
+				})
+
+				it("returns an error", func() {
+					err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+					Expect(err).To(MatchError(`failed to validate installer of miniconda3: unsupported checksum algorithm "sha512"`))
+				})
+			})
+
+			context("when the installer is not a shell script", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(scriptDir, "artifact.sh"), []byte("\x7fELF"), 0644)).To(Succeed())
+					dependency.Checksum = "sha256:" + sha256Hex("\x7fELF")
+				})
+
+				it("returns an error", func() {
+					err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+					Expect(err).To(MatchError(fmt.Sprintf("failed to validate installer of miniconda3: %s is not a shell script", filepath.Join(scriptDir, "artifact.sh"))))
+					Expect(executable.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			context("when the shell script is not a conda installer", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(scriptDir, "artifact.sh"), []byte("#!/bin/sh\nrm -rf /\n"), 0644)).To(Succeed())
+					dependency.Checksum = "sha256:" + sha256Hex("#!/bin/sh\nrm -rf /\n")
+				})
+
+				it("returns an error", func() {
+					err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{})
+					Expect(err).To(MatchError(fmt.Sprintf("failed to validate installer of miniconda3: %s is not a conda installer as it has no @@END_HEADER@@ marker", filepath.Join(scriptDir, "artifact.sh"))))
+					Expect(executable.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			context("when the script does not complete within the timeout", func() {
+				it.Before(func() {
+					executable.ExecuteCall.Stub = func(ctx gocontext.Context, execution pexec.Execution) error {
+						<-ctx.Done()
+						return errors.New("signal: killed")
+					}
+				})
+
+				it("kills the script and returns an error", func() {
+					err := shellInstaller.Install(dependency, scriptDir, layersDir, miniconda.InstallOptions{
+						Timeout: 10 * time.Millisecond,
+					})
+					Expect(err).To(MatchError("failed while running miniconda3 install script: timed out after 10ms"))
+
+					_, ok := executable.ExecuteCall.Receives.Ctx.Deadline()
+					Expect(ok).To(BeTrue())
+				})
+			})
+		})
+	})
+
+	context("CommandExecutable", func() {
+		it("runs the executable", func() {
+			buffer := bytes.NewBuffer(nil)
+			err := miniconda.NewCommandExecutable("bash").Execute(gocontext.Background(), pexec.Execution{
+				Args:   []string{"-c", "echo \"$0 in $(pwd)\""},
+				Dir:    layersDir,
+				Stdout: buffer,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			dir, err := filepath.EvalSymlinks(layersDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.String()).To(Equal(fmt.Sprintf("bash in %s\n", dir)))
+		})
+
+		it("kills the executable when the context is done", func() {
+			ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err := miniconda.NewCommandExecutable("bash").Execute(ctx, pexec.Execution{
+				Args: []string{"-c", "exec sleep 30"},
+			})
+			Expect(err).To(MatchError("signal: killed"))
+			Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package miniconda
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+// SolverChannelID is the id of the buildpack.toml dependency that contains a
+// local conda channel with the conda-libmamba-solver package and its
+// dependencies, so that the solver can be installed without network access.
+const SolverChannelID = "conda-libmamba-solver-channel"
+
+// Solver is the dependency solver that conda is configured to use.
+type Solver string
+
+const (
+	// ClassicSolver is the solver that conda uses by default.
+	ClassicSolver Solver = "classic"
+
+	// LibmambaSolver is the solver provided by the conda-libmamba-solver
+	// package.
+	LibmambaSolver Solver = "libmamba"
+)
+
+// DeprecatedClassicSolver is the name that earlier versions of the buildpack
+// used for the classic solver, which is still accepted as an alias.
+const DeprecatedClassicSolver = "conda"
+
+// ParseSolver converts a user provided solver name into a Solver. The value
+// "mamba" is accepted as an alias of "libmamba", the deprecated
+// DeprecatedClassicSolver as an alias of "classic", and an empty value
+// selects the classic solver.
+func ParseSolver(value string) (Solver, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "classic", DeprecatedClassicSolver:
+		return ClassicSolver, nil
+	case "libmamba", "mamba":
+		return LibmambaSolver, nil
+	default:
+		return "", fmt.Errorf("unsupported solver %q: must be one of classic, libmamba or mamba", value)
+	}
+}
+
+// lookupSolver reads the solver from $BP_MINICONDA_SOLVER, falling back to
+// the deprecated $BP_CONDA_SOLVER and then to project.toml.
+func lookupSolver(config Config, logger scribe.Emitter) (Solver, error) {
+	name := "$BP_MINICONDA_SOLVER"
+	value, ok := os.LookupEnv("BP_MINICONDA_SOLVER")
+
+	if legacy, found := os.LookupEnv("BP_CONDA_SOLVER"); found {
+		logger.Process("WARNING: $BP_CONDA_SOLVER is deprecated and will be removed in a future version, use $BP_MINICONDA_SOLVER instead")
+		logger.Break()
+
+		if !ok {
+			name, value, ok = "$BP_CONDA_SOLVER", legacy, true
+		}
+	}
+
+	if !ok {
+		name, value = config.Name("BP_MINICONDA_SOLVER"), config.Lookup("BP_MINICONDA_SOLVER", "")
+	}
+
+	solver, err := ParseSolver(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	if strings.EqualFold(strings.TrimSpace(value), DeprecatedClassicSolver) {
+		logger.Process("WARNING: solver %q of %s is deprecated and will be removed in a future version, use %q instead", DeprecatedClassicSolver, name, ClassicSolver)
+		logger.Break()
+	}
+
+	return solver, nil
+}
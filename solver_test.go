@@ -0,0 +1,40 @@
+package miniconda_test
+
+import (
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSolver(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("ParseSolver", func() {
+		it("parses the supported solver names", func() {
+			for value, expected := range map[string]miniconda.Solver{
+				"":         miniconda.ClassicSolver,
+				"classic":  miniconda.ClassicSolver,
+				"conda":    miniconda.ClassicSolver,
+				"libmamba": miniconda.LibmambaSolver,
+				"mamba":    miniconda.LibmambaSolver,
+				" Mamba ":  miniconda.LibmambaSolver,
+			} {
+				solver, err := miniconda.ParseSolver(value)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(solver).To(Equal(expected), value)
+			}
+		})
+
+		context("failure cases", func() {
+			context("when the solver is not recognized", func() {
+				it("returns an error", func() {
+					_, err := miniconda.ParseSolver("anaconda")
+					Expect(err).To(MatchError(`unsupported solver "anaconda": must be one of classic, libmamba or mamba`))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package miniconda
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// TarballInstaller implements the Installer interface for archives that
+// contain executables instead of an installer script.
+type TarballInstaller struct{}
+
+// NewTarballInstaller creates an instance of the TarballInstaller.
+func NewTarballInstaller() TarballInstaller {
+	return TarballInstaller{}
+}
+
+// Install installs the executables in the bin directory of the archive that
+// postal extracted into artifactPath into the layer at condaLayerPath. The
+// layer becomes a root prefix with an empty base environment. No install
+// options are supported.
+func (t TarballInstaller) Install(dependency postal.Dependency, artifactPath, condaLayerPath string, options InstallOptions) error {
+	err := options.requireNone(dependency, TarballInstallerKind)
+	if err != nil {
+		return err
+	}
+
+	source := filepath.Join(artifactPath, "bin")
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("failed to install %s: %s does not contain a bin directory", dependency.ID, artifactPath)
+		}
+		return err
+	}
+
+	for _, dir := range []string{"bin", "conda-meta", "pkgs"} {
+		err = os.MkdirAll(filepath.Join(condaLayerPath, dir), os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("failed to install %s: %w", dependency.ID, err)
+		}
+	}
+
+	for _, entry := range entries {
+		executable := filepath.Join(condaLayerPath, "bin", entry.Name())
+		err = fs.Copy(filepath.Join(source, entry.Name()), executable)
+		if err != nil {
+			return fmt.Errorf("failed to install %s: %w", dependency.ID, err)
+		}
+
+		if !entry.IsDir() {
+			err = os.Chmod(executable, 0755)
+			if err != nil {
+				return fmt.Errorf("failed to install %s: %w", dependency.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package miniconda_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/miniconda"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testTarballInstaller(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layerPath  string
+		tarballDir string
+		dependency postal.Dependency
+
+		tarballInstaller miniconda.TarballInstaller
+	)
+
+	it.Before(func() {
+		var err error
+		layerPath, err = os.MkdirTemp("", "conda-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		tarballDir, err = os.MkdirTemp("", "conda-tarball")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(tarballDir, "bin"), os.ModePerm)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tarballDir, "bin", "conda"), []byte("conda-binary"), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(tarballDir, "info"), os.ModePerm)).To(Succeed())
+
+		dependency = postal.Dependency{
+			ID:   "some-conda",
+			Name: "some-conda-bin.tar.bz2",
+		}
+
+		tarballInstaller = miniconda.NewTarballInstaller()
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layerPath)).To(Succeed())
+		Expect(os.RemoveAll(tarballDir)).To(Succeed())
+	})
+
+	context("Install", func() {
+		it("installs the executables into a root prefix", func() {
+			err := tarballInstaller.Install(dependency, tarballDir, layerPath, miniconda.InstallOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(layerPath, "bin", "conda")).To(BeARegularFile())
+			content, err := os.ReadFile(filepath.Join(layerPath, "bin", "conda"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("conda-binary"))
+
+			info, err := os.Stat(filepath.Join(layerPath, "bin", "conda"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+
+			Expect(filepath.Join(layerPath, "conda-meta")).To(BeADirectory())
+			Expect(filepath.Join(layerPath, "pkgs")).To(BeADirectory())
+			Expect(filepath.Join(layerPath, "info")).NotTo(BeADirectory())
+		})
+
+		context("failure cases", func() {
+			context("when install options are given", func() {
+				it("returns an error", func() {
+					err := tarballInstaller.Install(dependency, tarballDir, layerPath, miniconda.InstallOptions{
+						Env:        []string{"SOME_VAR=some-value"},
+						WorkingDir: tarballDir,
+					})
+					Expect(err).To(MatchError("failed to install some-conda: the tarball installer does not support the install options Env, WorkingDir"))
+					Expect(filepath.Join(layerPath, "bin")).NotTo(BeADirectory())
+				})
+			})
+
+			context("when the tarball does not contain a bin directory", func() {
+				it.Before(func() {
+					Expect(os.RemoveAll(filepath.Join(tarballDir, "bin"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					err := tarballInstaller.Install(dependency, tarballDir, layerPath, miniconda.InstallOptions{})
+					Expect(err).To(MatchError(ContainSubstring("failed to install some-conda: %s does not contain a bin directory", tarballDir)))
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,183 @@
+package miniconda
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+	"gopkg.in/yaml.v3"
+)
+
+// VendorDir is the directory of the application that contains a vendored
+// conda channel, as created by `conda index vendor`.
+const VendorDir = "vendor"
+
+// VendoredChannel is a local conda channel that the application environment
+// is installed from without network access.
+type VendoredChannel struct {
+	Path string
+}
+
+// findVendoredChannel returns the vendored channel of the application, which
+// is the vendor directory when it has been indexed, or nothing when there is
+// none.
+func findVendoredChannel(workingDir string) (VendoredChannel, bool, error) {
+	channel := VendoredChannel{Path: filepath.Join(workingDir, VendorDir)}
+
+	exists, err := fs.Exists(filepath.Join(channel.Path, "channeldata.json"))
+	if err != nil {
+		return VendoredChannel{}, false, err
+	}
+
+	if exists {
+		return channel, true, nil
+	}
+
+	indexes, err := channel.indexes()
+	if err != nil {
+		return VendoredChannel{}, false, err
+	}
+
+	return channel, len(indexes) > 0, nil
+}
+
+// indexes returns the repodata.json files of every subdir of the channel.
+func (c VendoredChannel) indexes() ([]string, error) {
+	return filepath.Glob(filepath.Join(c.Path, "*", "repodata.json"))
+}
+
+// Checksum returns a checksum of the indexes of the channel, which changes
+// whenever packages are added to or removed from the channel.
+func (c VendoredChannel) Checksum() (string, error) {
+	indexes, err := c.indexes()
+	if err != nil {
+		return "", err
+	}
+
+	if len(indexes) == 0 {
+		return "", nil
+	}
+
+	return fs.NewChecksumCalculator().Sum(indexes...)
+}
+
+// Packages returns the names of the packages in the channel.
+func (c VendoredChannel) Packages() (map[string]bool, error) {
+	indexes, err := c.indexes()
+	if err != nil {
+		return nil, err
+	}
+
+	packages := map[string]bool{}
+	for _, index := range indexes {
+		content, err := os.ReadFile(index)
+		if err != nil {
+			return nil, err
+		}
+
+		var repodata struct {
+			Packages      map[string]struct{ Name string } `json:"packages"`
+			CondaPackages map[string]struct{ Name string } `json:"packages.conda"`
+		}
+
+		err = json.Unmarshal(content, &repodata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", index, err)
+		}
+
+		for _, p := range repodata.Packages {
+			packages[p.Name] = true
+		}
+
+		for _, p := range repodata.CondaPackages {
+			packages[p.Name] = true
+		}
+	}
+
+	return packages, nil
+}
+
+// environmentSpecs returns the conda package specs listed as dependencies in
+// the environment file. As the specs are installed from the vendored channel
+// only, the channel of specs such as "conda-forge::numpy" is removed.
+// Dependencies that are installed with pip cannot be installed from a conda
+// channel and result in an error.
+func environmentSpecs(envFile string) ([]string, error) {
+	content, err := os.ReadFile(envFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var environment struct {
+		Dependencies []interface{} `yaml:"dependencies"`
+	}
+
+	err = yaml.Unmarshal(content, &environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(envFile), err)
+	}
+
+	var specs []string
+	for _, dependency := range environment.Dependencies {
+		switch d := dependency.(type) {
+		case string:
+			specs = append(specs, withoutChannel(d))
+		case map[string]interface{}:
+			if _, ok := d["pip"]; ok {
+				return nil, fmt.Errorf("the pip dependencies of %s cannot be installed from a vendored conda channel", filepath.Base(envFile))
+			}
+
+			return nil, fmt.Errorf("unsupported dependency in %s: %v", filepath.Base(envFile), d)
+		default:
+			return nil, fmt.Errorf("unsupported dependency in %s: %v", filepath.Base(envFile), d)
+		}
+	}
+
+	return specs, nil
+}
+
+// withoutChannel removes the channel from a conda package spec such as
+// "conda-forge::numpy>=1.26".
+func withoutChannel(spec string) string {
+	if _, s, found := strings.Cut(spec, "::"); found {
+		return strings.TrimSpace(s)
+	}
+
+	return spec
+}
+
+// specName returns the name of the package of a conda package spec such as
+// "conda-forge::numpy>=1.26".
+func specName(spec string) string {
+	spec = withoutChannel(spec)
+
+	if i := strings.IndexAny(spec, " =<>!~["); i >= 0 {
+		spec = spec[:i]
+	}
+
+	return strings.TrimSpace(spec)
+}
+
+// missingPackages returns the names of the packages of the given specs that
+// are not in the channel, sorted by name.
+func (c VendoredChannel) missingPackages(specs []string) ([]string, error) {
+	packages, err := c.Packages()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, spec := range specs {
+		if name := specName(spec); !packages[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing, nil
+}
@@ -0,0 +1,50 @@
+package miniconda
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/postal"
+)
+
+// CondaInfo holds the parts of the output of `conda info --json` that
+// describe the installation.
+type CondaInfo struct {
+	CondaVersion  string `json:"conda_version"`
+	PythonVersion string `json:"python_version"`
+	Platform      string `json:"platform"`
+}
+
+// verifyInstallation runs `conda info --json` in the given conda layer and
+// checks that the installed conda is the version of the dependency.
+func verifyInstallation(condaRunner CondaRunner, condaLayer packit.Layer, dependency postal.Dependency) (CondaInfo, error) {
+	buffer := bytes.NewBuffer(nil)
+	err := condaRunner.Execute(condaLayer.Path, pexec.Execution{
+		Args:   []string{"info", "--json"},
+		Stdout: buffer,
+	})
+	if err != nil {
+		return CondaInfo{}, fmt.Errorf("failed to verify conda installation: %w", err)
+	}
+
+	var info CondaInfo
+	err = json.Unmarshal(buffer.Bytes(), &info)
+	if err != nil {
+		return CondaInfo{}, fmt.Errorf("failed to verify conda installation: failed to parse conda info: %w", err)
+	}
+
+	if info.CondaVersion != dependency.Version {
+		return CondaInfo{}, fmt.Errorf("failed to verify conda installation: installed conda version %q does not match the version %q of dependency %s", info.CondaVersion, dependency.Version, dependency.ID)
+	}
+
+	// Python reports its version as e.g. "3.12.1.final.0".
+	if parts := strings.Split(info.PythonVersion, "."); len(parts) > 3 {
+		info.PythonVersion = strings.Join(parts[:3], ".")
+	}
+
+	return info, nil
+}